@@ -16,8 +16,14 @@ func (s Scope) AllTypes() iter.Seq[reflect.Type] {
 		if !yield(reflect.TypeFor[Reset]()) {
 			return
 		}
+		if !yield(reflect.TypeFor[Scope]()) {
+			return
+		}
+		if !yield(reflect.TypeFor[Call]()) {
+			return
+		}
 		for value := range s.values.IterValues() {
-			// Always-provided types (e.g. InjectStruct, Fork, Reset) are emitted above as
+			// Always-provided types (e.g. InjectStruct, Fork, Reset, Scope, Call) are emitted above as
 			// built-ins. They may also appear in s.values if a user provides a
 			// definition for them, but Scope.get ignores such definitions and
 			// always returns the built-in. Skip them here to avoid yielding the
@@ -25,9 +31,19 @@ func (s Scope) AllTypes() iter.Seq[reflect.Type] {
 			if isAlwaysProvided(value.typeInfo.TypeID) {
 				continue
 			}
+			// private types are not resolvable through the scope
+			if _, ok := privateTypeModule(value.typeInfo.TypeID); ok {
+				continue
+			}
 			if !yield(typeIDToType(value.typeInfo.TypeID)) {
 				return
 			}
 		}
+		// lazily resolved types
+		for _, id := range s.resolvers.types() {
+			if !yield(typeIDToType(id)) {
+				return
+			}
+		}
 	}
 }
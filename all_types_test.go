@@ -22,7 +22,7 @@ func TestAllTypes(t *testing.T) {
 		names = append(names, fmt.Sprintf("%v", t))
 	}
 	slices.Sort(names)
-	if str := fmt.Sprintf("%v", names); str != "[dscope.Fork dscope.InjectStruct dscope.Reset float64 int32 int64 string]" {
+	if str := fmt.Sprintf("%v", names); str != "[dscope.Call dscope.Fork dscope.InjectStruct dscope.Reset dscope.Scope float64 int32 int64 string]" {
 		t.Fatalf("got %v", str)
 	}
 
@@ -39,7 +39,7 @@ func TestAllTypes(t *testing.T) {
 		names = append(names, fmt.Sprintf("%v", t))
 	}
 	slices.Sort(names)
-	if str := fmt.Sprintf("%v", names); str != "[dscope.Fork dscope.InjectStruct dscope.Reset float64 int32 int64 int8 string]" {
+	if str := fmt.Sprintf("%v", names); str != "[dscope.Call dscope.Fork dscope.InjectStruct dscope.Reset dscope.Scope float64 int32 int64 int8 string]" {
 		t.Fatalf("got %v", str)
 	}
 
@@ -1,7 +1,6 @@
 package dscope
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
 )
@@ -18,29 +17,25 @@ func (c CallResult) Extract(targets ...any) {
 			continue
 		}
 		if i >= len(c.Values) {
-			panic(errors.Join(
-				fmt.Errorf("not enough values for targets: have %d, want at least %d", len(c.Values), i+1),
-				ErrBadArgument,
-			))
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("not enough values for targets: have %d, want at least %d", len(c.Values), i+1),
+			})
 		}
 		targetValue := reflect.ValueOf(target)
 		if targetValue.Kind() != reflect.Pointer {
-			panic(errors.Join(
-				fmt.Errorf("%T is not a pointer", target),
-				ErrBadArgument,
-			))
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("%T is not a pointer", target),
+			})
 		}
 		if targetValue.IsNil() { // prevent reflect panics on nil pointers
-			panic(errors.Join(
-				fmt.Errorf("cannot assign to a nil pointer target of type %v", targetValue.Type()),
-				ErrBadArgument,
-			))
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("cannot assign to a nil pointer target of type %v", targetValue.Type()),
+			})
 		}
 		if !c.Values[i].Type().AssignableTo(targetValue.Type().Elem()) {
-			panic(errors.Join(
-				fmt.Errorf("cannot assign value of type %v to target of type %v", c.Values[i].Type(), targetValue.Type().Elem()),
-				ErrBadArgument,
-			))
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("cannot assign value of type %v to target of type %v", c.Values[i].Type(), targetValue.Type().Elem()),
+			})
 		}
 		targetValue.Elem().Set(c.Values[i])
 	}
@@ -57,10 +52,10 @@ func (c CallResult) Assign(targets ...any) {
 		}
 		v := reflect.ValueOf(target)
 		if v.Kind() != reflect.Pointer {
-			panic(errors.Join(fmt.Errorf("%v is not a pointer", target), ErrBadArgument))
+			panic(&BadArgumentError{Reason: fmt.Sprintf("%v is not a pointer", target)})
 		}
 		if v.IsNil() {
-			panic(errors.Join(fmt.Errorf("cannot assign to a nil pointer target of type %v", v.Type()), ErrBadArgument))
+			panic(&BadArgumentError{Reason: fmt.Sprintf("cannot assign to a nil pointer target of type %v", v.Type())})
 		}
 		targetValues[i] = v
 	}
@@ -86,7 +81,7 @@ func (c CallResult) Assign(targets ...any) {
 	}
 	for i, ok := range satisfied {
 		if !ok {
-			panic(errors.Join(fmt.Errorf("no return values of type %v", targetValues[i].Type().Elem()), ErrBadArgument))
+			panic(&BadArgumentError{Reason: fmt.Sprintf("no return values of type %v", targetValues[i].Type().Elem())})
 		}
 	}
 }
\ No newline at end of file
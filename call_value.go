@@ -0,0 +1,22 @@
+package dscope
+
+import "reflect"
+
+const TheoryOfScopeCallValue = `
+dscope call value theory:
+- The Call type represents the Call method bound to a specific scope instance.
+- It is always provided as a built-in dependency so that consumers can call
+  functions with dependencies resolved from the current scope.
+- Like other always-provided types, user definitions for Call are ignored in
+  favor of the built-in binding to the current scope.
+- Call is opaque to dependency analysis: a provider receiving it can resolve
+  any type through the called functions. Providers depending on Call are
+  therefore pessimistically re-evaluated whenever a scope is forked with new
+  definitions, mirroring the InjectStruct, Fork and Reset treatment.
+`
+
+// Call is the type of a scope's Call method, bound to the scope that provides
+// it. It is always provided as a built-in dependency.
+type Call func(fn any) CallResult
+
+var callTypeID = getTypeID(reflect.TypeFor[Call]())
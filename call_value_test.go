@@ -0,0 +1,80 @@
+package dscope
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetCallValue(t *testing.T) {
+	scope := New(func() int {
+		return 42
+	})
+	call := Get[Call](scope)
+	if call == nil {
+		t.Fatal("got nil Call")
+	}
+	var n int
+	call(func(i int) int {
+		return i
+	}).Assign(&n)
+	if n != 42 {
+		t.Fatalf("got %d", n)
+	}
+}
+
+func TestCallWithCallDependency(t *testing.T) {
+	type Service int
+	scope := New(
+		func() int {
+			return 42
+		},
+		func(call Call) (ret Service) {
+			call(func(i int) {
+				ret = Service(i)
+			})
+			return
+		},
+	)
+	if s := Get[Service](scope); s != 42 {
+		t.Fatalf("got %d", s)
+	}
+}
+
+func TestCallValueReflectGet(t *testing.T) {
+	scope := New(func() int {
+		return 42
+	})
+	v, ok := scope.Get(reflect.TypeFor[Call]())
+	if !ok {
+		t.Fatal("Get(reflect.Type) returned ok=false")
+	}
+	if _, ok := v.Interface().(Call); !ok {
+		t.Fatalf("got %v", v.Type())
+	}
+}
+
+func TestCallDependencyResetForNewDefs(t *testing.T) {
+	type Config int
+	type Service int
+	var counter int
+	scope := New(
+		func() Config { return 1 },
+		func(call Call) (ret Service) {
+			counter++
+			call(func(c Config) {
+				ret = Service(c)
+			})
+			return
+		},
+	)
+	if s := Get[Service](scope); s != 1 {
+		t.Fatalf("expected 1, got %d", s)
+	}
+	child := scope.Fork(func() Config { return 3 })
+	if s := Get[Service](child); s != 3 {
+		t.Fatalf("expected 3, got %d", s)
+	}
+	if counter != 2 {
+		t.Fatalf("expected provider to run twice, got %d", counter)
+	}
+}
@@ -0,0 +1,90 @@
+package dscope
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const TheoryOfConditionalDefinitions = `
+dscope conditional definition theory:
+- When(predicate, defs...) includes defs in a Fork only if the predicate
+  returns true. The predicate's parameters are resolved from the parent scope,
+  the scope being forked, so one module list can adapt to values such as an
+  environment profile already held by the scope.
+- Predicates are evaluated on every Fork, before the Fork cache key is derived.
+  The key covers the selected definitions, so cached forkers stay correct when
+  a predicate changes its answer.
+- Conditional definitions may contain any definition accepted by Fork,
+  including modules and nested conditionals.
+`
+
+// _Conditional is a group of definitions included only if Predicate returns
+// true.
+type _Conditional struct {
+	Predicate reflect.Value
+	Defs      []any
+}
+
+// When returns a definition including defs in a Fork if predicate, a function
+// returning bool with dependencies resolved from the parent scope, returns true.
+func When(predicate any, defs ...any) any {
+	predicateValue := reflect.ValueOf(predicate)
+	predicateType := validateCallableValue(predicateValue)
+	if predicateType.NumOut() != 1 || predicateType.Out(0).Kind() != reflect.Bool {
+		panic(&BadArgumentError{
+			Reason: fmt.Sprintf("predicate %v does not return bool", predicateType),
+		})
+	}
+	return _Conditional{
+		Predicate: predicateValue,
+		Defs:      defs,
+	}
+}
+
+// expandConditionals replaces conditional definitions in defs with their
+// definitions if their predicates hold in scope. If validation is not nil,
+// predicates are not evaluated and every conditional definition is marked and
+// kept.
+func expandConditionals(scope Scope, defs []any, validation *_Validation) []any {
+	found := false
+	for _, def := range defs {
+		if _, ok := def.(_Conditional); ok {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return defs
+	}
+	// build a new slice to avoid modifying the caller's slice
+	ret := make([]any, 0, len(defs))
+	for _, def := range defs {
+		conditional, ok := def.(_Conditional)
+		if !ok {
+			ret = append(ret, def)
+			continue
+		}
+		if validation != nil {
+			validation.requirePredicate(conditional.Predicate)
+		} else if !scope.CallValue(conditional.Predicate).Values[0].Bool() {
+			continue
+		}
+		for _, def := range conditional.Defs {
+			if def == nil {
+				panic(&BadArgumentError{
+					Reason: "nil definition",
+				})
+			}
+		}
+		expanded := expandConditionals(scope, conditional.Defs, validation)
+		if validation != nil {
+			for i, def := range expanded {
+				if _, ok := def.(Resolver); !ok {
+					expanded[i] = annotate(def, defConditional)
+				}
+			}
+		}
+		ret = append(ret, expanded...)
+	}
+	return ret
+}
@@ -0,0 +1,118 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testConditionalProfile string
+
+type testConditionalStore string
+
+type testConditionalFakes struct {
+	Module
+}
+
+func (testConditionalFakes) Store() testConditionalStore {
+	return "fake"
+}
+
+func TestWhen(t *testing.T) {
+	defs := []any{
+		When(
+			func(profile testConditionalProfile) bool {
+				return profile != "dev"
+			},
+			func() testConditionalStore {
+				return "real"
+			},
+		),
+		When(
+			func(profile testConditionalProfile) bool {
+				return profile == "dev"
+			},
+			new(testConditionalFakes),
+		),
+	}
+
+	prod := New(Provide(testConditionalProfile("prod"))).Fork(defs...)
+	if s := Get[testConditionalStore](prod); s != "real" {
+		t.Fatalf("got %s", s)
+	}
+
+	dev := New(Provide(testConditionalProfile("dev"))).Fork(defs...)
+	if s := Get[testConditionalStore](dev); s != "fake" {
+		t.Fatalf("got %s", s)
+	}
+}
+
+func TestWhenCacheKey(t *testing.T) {
+	enabled := true
+	defs := []any{
+		Provide(1),
+		When(
+			func() bool {
+				return enabled
+			},
+			func(i int) string {
+				return "enabled"
+			},
+		),
+	}
+	parent := New()
+	a := parent.Fork(defs...)
+	if s := Get[string](a); s != "enabled" {
+		t.Fatalf("got %s", s)
+	}
+	enabled = false
+	b := parent.Fork(defs...)
+	if _, ok := b.Get(reflect.TypeFor[string]()); ok {
+		t.Fatal("conditional definition should not be included")
+	}
+	if a.forkFuncKey == b.forkFuncKey {
+		t.Fatal("cache key should cover the selected definitions")
+	}
+}
+
+func TestWhenNested(t *testing.T) {
+	scope := New(
+		When(
+			func() bool { return true },
+			When(
+				func() bool { return true },
+				Provide(42),
+			),
+		),
+	)
+	if i := Get[int](scope); i != 42 {
+		t.Fatalf("got %d", i)
+	}
+}
+
+func TestWhenBadArgument(t *testing.T) {
+	for _, fn := range []func(){
+		func() {
+			When(42)
+		},
+		func() {
+			When(func() int { return 1 })
+		},
+		func() {
+			New(When(func() bool { return true }, nil))
+		},
+	} {
+		func() {
+			defer func() {
+				p := recover()
+				if p == nil {
+					t.Fatal("should panic")
+				}
+				if err, ok := p.(error); !ok || !errors.Is(err, ErrBadArgument) {
+					t.Fatalf("got %v", p)
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -1,5 +1,11 @@
 package dscope
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
 // TheoryOfConstructors documents the constructor convention for dscope users:
 // how constructor types, their providers, and the built values are named and
 // declared.
@@ -17,4 +23,31 @@ dscope constructor theory:
 - Consumers depend on the constructor type, not on the concrete value. They
   call the constructor and handle the error where the value is needed, which
   keeps construction visible, testable, and replaceable.
+- Construct[NewFoo]() opts a constructor into direct consumption: it is an
+  ordinary provider depending on NewFoo and returning *Foo, so the constructor
+  runs lazily, its result is cached per scope, and the graph shows the edge
+  from NewFoo to *Foo. A constructor error panics with ErrProviderFailed and,
+  like any provider panic, is not cached.
 `
+
+// Construct returns a definition providing the value built by the constructor
+// type C. Consumers in a scope defining both C and Construct[C]() may depend on
+// the built type directly.
+func Construct[C ~func() (T, error), T any]() func(C) T {
+	return func(construct C) T {
+		if construct == nil {
+			panic(errors.Join(
+				fmt.Errorf("nil constructor %v", reflect.TypeFor[C]()),
+				ErrBadDefinition,
+			))
+		}
+		value, err := construct()
+		if err != nil {
+			panic(errors.Join(
+				fmt.Errorf("constructor %v failed: %w", reflect.TypeFor[C](), err),
+				ErrProviderFailed,
+			))
+		}
+		return value
+	}
+}
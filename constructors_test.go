@@ -0,0 +1,140 @@
+package dscope
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testConstructorsFoo struct {
+	N int
+}
+
+type NewTestConstructorsFoo func() (*testConstructorsFoo, error)
+
+func TestConstruct(t *testing.T) {
+	var calls int
+	scope := New(
+		func() int {
+			return 42
+		},
+		func(n int) NewTestConstructorsFoo {
+			return func() (*testConstructorsFoo, error) {
+				calls++
+				return &testConstructorsFoo{N: n}, nil
+			}
+		},
+		Construct[NewTestConstructorsFoo](),
+	)
+	if calls != 0 {
+		t.Fatal("constructor should be called lazily")
+	}
+
+	foo := Get[*testConstructorsFoo](scope)
+	if foo.N != 42 {
+		t.Fatalf("got %d", foo.N)
+	}
+	if Get[*testConstructorsFoo](scope) != foo {
+		t.Fatal("constructed value should be cached")
+	}
+	if calls != 1 {
+		t.Fatalf("expected one call, got %d", calls)
+	}
+
+	// overriding a dependency of the constructor rebuilds the value
+	child := scope.Fork(func() int {
+		return 1
+	})
+	if n := Get[*testConstructorsFoo](child).N; n != 1 {
+		t.Fatalf("got %d", n)
+	}
+	if calls != 2 {
+		t.Fatalf("expected two calls, got %d", calls)
+	}
+}
+
+func TestConstructError(t *testing.T) {
+	errFoo := errors.New("foo")
+	var calls int
+	scope := New(
+		func() NewTestConstructorsFoo {
+			return func() (*testConstructorsFoo, error) {
+				calls++
+				return nil, errFoo
+			}
+		},
+		Construct[NewTestConstructorsFoo](),
+	)
+	for i := range 2 {
+		func() {
+			defer func() {
+				p := recover()
+				if p == nil {
+					t.Fatal("should panic")
+				}
+				err, ok := p.(error)
+				if !ok {
+					t.Fatalf("got %#v", p)
+				}
+				if !errors.Is(err, ErrProviderFailed) {
+					t.Fatalf("got %v", err)
+				}
+				if !errors.Is(err, errFoo) {
+					t.Fatalf("got %v", err)
+				}
+			}()
+			Get[*testConstructorsFoo](scope)
+		}()
+		if calls != i+1 {
+			t.Fatalf("constructor error should not be cached, got %d calls", calls)
+		}
+	}
+}
+
+func TestConstructNil(t *testing.T) {
+	scope := New(
+		func() NewTestConstructorsFoo {
+			return nil
+		},
+		Construct[NewTestConstructorsFoo](),
+	)
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		if err, ok := p.(error); !ok || !errors.Is(err, ErrBadDefinition) {
+			t.Fatalf("got %v", p)
+		}
+	}()
+	Get[*testConstructorsFoo](scope)
+}
+
+func TestConstructToDOT(t *testing.T) {
+	scope := New(
+		func() NewTestConstructorsFoo {
+			return func() (*testConstructorsFoo, error) {
+				return new(testConstructorsFoo), nil
+			}
+		},
+		Construct[NewTestConstructorsFoo](),
+	)
+	buf := new(strings.Builder)
+	if err := scope.ToDOT(buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	if !strings.Contains(dot, "Type: *dscope.testConstructorsFoo\\nDefined By: func(dscope.NewTestConstructorsFoo) *dscope.testConstructorsFoo") {
+		t.Fatalf("constructed type missing from graph:\n%s", dot)
+	}
+	edge := fmt.Sprintf(
+		"\"%d\" -> \"%d\"",
+		getTypeID(reflect.TypeFor[NewTestConstructorsFoo]()),
+		getTypeID(reflect.TypeFor[*testConstructorsFoo]()),
+	)
+	if !strings.Contains(dot, edge) {
+		t.Fatalf("missing edge from constructor:\n%s", dot)
+	}
+}
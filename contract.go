@@ -0,0 +1,114 @@
+package dscope
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+const TheoryOfModuleContracts = `
+dscope module contract theory:
+- A module implementing RequiringModule declares the types it requires from
+  its environment. Every required type must be defined by a definition outside
+  the module, or be a built-in.
+- A module implementing ExportingModule declares the types it exports. Every
+  exported type must be defined by the module, and definitions outside the
+  module may depend only on exported types of the module. Types of modules
+  not implementing ExportingModule are all exported.
+- Contracts are verified by Fork during dependency analysis, so a scope that
+  violates a contract is never created. All violations are reported at once,
+  each naming the module type.
+- Contracts constrain definitions only. Opaque dependencies such as Scope or
+  Call, and Get or Call on the scope, are not restricted.
+`
+
+// RequiringModule is implemented by modules that declare the types they
+// require from other definitions.
+type RequiringModule interface {
+	isModule
+	Requires() []reflect.Type
+}
+
+var requiringModuleType = reflect.TypeFor[RequiringModule]()
+
+// ExportingModule is implemented by modules that declare the types other
+// definitions may depend on.
+type ExportingModule interface {
+	isModule
+	Exports() []reflect.Type
+}
+
+var exportingModuleType = reflect.TypeFor[ExportingModule]()
+
+// checkModuleContracts returns the violations of contracts of modules
+// defining values.
+func checkModuleContracts(values *_StackedMap) error {
+	modulesSet := make(map[*_ModuleInfo]struct{})
+	for value := range values.IterValues() {
+		if module := value.typeInfo.Module; module != nil {
+			modulesSet[module] = struct{}{}
+		}
+	}
+	if len(modulesSet) == 0 {
+		return nil
+	}
+	modules := make([]*_ModuleInfo, 0, len(modulesSet))
+	for module := range modulesSet {
+		modules = append(modules, module)
+	}
+	slices.SortFunc(modules, func(a, b *_ModuleInfo) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	var errs []error
+	for _, module := range modules {
+		for _, t := range module.Requires {
+			id := getTypeID(t)
+			if isAlwaysProvided(id) {
+				continue
+			}
+			if value, ok := values.Load(id); ok && value.typeInfo.Module != module {
+				continue
+			}
+			errs = append(errs, &DependencyNotFoundError{
+				Type:       t,
+				RequiredBy: module.Type,
+				Source:     fmt.Sprintf("Requires of module %v, outside the module", module.Type),
+			})
+		}
+		for _, t := range module.Exports {
+			if value, ok := values.Load(getTypeID(t)); ok && value.typeInfo.Module == module {
+				continue
+			}
+			errs = append(errs, errors.Join(
+				fmt.Errorf("module %v exports %v, which it does not define", module.Type, t),
+				ErrBadDefinition,
+			))
+		}
+	}
+
+	for value := range values.IterValues() {
+		for _, depID := range value.typeInfo.Dependencies {
+			depValue, ok := values.Load(depID)
+			if !ok {
+				continue
+			}
+			module := depValue.typeInfo.Module
+			if module == nil || !module.HasExports || module == value.typeInfo.Module {
+				continue
+			}
+			t := typeIDToType(depID)
+			if slices.Contains(module.Exports, t) {
+				continue
+			}
+			errs = append(errs, errors.Join(
+				fmt.Errorf("definition %v depends on %v, which module %v does not export", value.typeInfo.DefType, t, module.Type),
+				ErrBadDefinition,
+			))
+		}
+	}
+
+	return errors.Join(errs...)
+}
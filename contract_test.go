@@ -0,0 +1,176 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testContractConfig string
+
+type testContractInternal string
+
+type testContractService string
+
+type testContractModule struct {
+	Module
+}
+
+func (testContractModule) Requires() []reflect.Type {
+	return []reflect.Type{
+		reflect.TypeFor[testContractConfig](),
+	}
+}
+
+func (testContractModule) Exports() []reflect.Type {
+	return []reflect.Type{
+		reflect.TypeFor[testContractService](),
+	}
+}
+
+func (testContractModule) Internal(c testContractConfig) testContractInternal {
+	return testContractInternal(c)
+}
+
+func (testContractModule) Service(i testContractInternal) testContractService {
+	return testContractService(i)
+}
+
+func TestModuleContract(t *testing.T) {
+	scope := New(
+		new(testContractModule),
+		func() testContractConfig {
+			return "config"
+		},
+		func(s testContractService) string {
+			return string(s)
+		},
+	)
+	if s := Get[string](scope); s != "config" {
+		t.Fatalf("got %v", s)
+	}
+	// not restricted for Get
+	Get[testContractInternal](scope)
+}
+
+func TestModuleContractRequires(t *testing.T) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		err, ok := p.(error)
+		if !ok || !errors.Is(err, ErrDependencyNotFound) {
+			t.Fatalf("got %v", p)
+		}
+		var notFound *DependencyNotFoundError
+		if !errors.As(err, &notFound) {
+			t.Fatalf("got %v", err)
+		}
+		if notFound.Type != reflect.TypeFor[testContractConfig]() ||
+			notFound.RequiredBy != reflect.TypeFor[testContractRequiringModule]() {
+			t.Fatalf("got %+v", notFound)
+		}
+		if !strings.Contains(err.Error(), "Requires of module dscope.testContractRequiringModule") {
+			t.Fatalf("got %v", err)
+		}
+	}()
+	New(new(testContractRequiringModule))
+}
+
+type testContractRequiringModule struct {
+	Module
+}
+
+func (testContractRequiringModule) Requires() []reflect.Type {
+	return []reflect.Type{
+		reflect.TypeFor[testContractConfig](),
+	}
+}
+
+func (testContractRequiringModule) Int() int {
+	return 42
+}
+
+func TestModuleContractExports(t *testing.T) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		err, ok := p.(error)
+		if !ok || !errors.Is(err, ErrBadDefinition) {
+			t.Fatalf("got %v", p)
+		}
+		if !strings.Contains(err.Error(), "which module dscope.testContractModule does not export") {
+			t.Fatalf("got %v", err)
+		}
+	}()
+	New(
+		new(testContractModule),
+		func() testContractConfig {
+			return "config"
+		},
+	).Fork(func(i testContractInternal) string {
+		return string(i)
+	})
+}
+
+type testContractBadExportModule struct {
+	Module
+}
+
+func (testContractBadExportModule) Exports() []reflect.Type {
+	return []reflect.Type{
+		reflect.TypeFor[testContractService](),
+	}
+}
+
+func (testContractBadExportModule) Int() int {
+	return 42
+}
+
+func TestModuleContractUndefinedExport(t *testing.T) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		err, ok := p.(error)
+		if !ok || !errors.Is(err, ErrBadDefinition) {
+			t.Fatalf("got %v", p)
+		}
+		if !strings.Contains(err.Error(), "module dscope.testContractBadExportModule exports dscope.testContractService") {
+			t.Fatalf("got %v", err)
+		}
+	}()
+	New(new(testContractBadExportModule))
+}
+
+func TestModuleContractSelfProvidedRequirement(t *testing.T) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		if err, ok := p.(error); !ok || !errors.Is(err, ErrDependencyNotFound) {
+			t.Fatalf("got %v", p)
+		}
+	}()
+	New(new(testContractSelfModule))
+}
+
+type testContractSelfModule struct {
+	Module
+}
+
+func (testContractSelfModule) Requires() []reflect.Type {
+	return []reflect.Type{
+		reflect.TypeFor[testContractConfig](),
+	}
+}
+
+func (testContractSelfModule) Config() testContractConfig {
+	return "self"
+}
@@ -0,0 +1,122 @@
+package dscope
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+)
+
+const TheoryOfReentrantResolution = `
+dscope re-entrant resolution theory:
+- A provider is evaluated while its initializer is locked. A provider that
+  resolves its own type, directly or through other providers, via Scope, Fork,
+  InjectStruct or Inject, would wait on that lock forever.
+- The scope handed to a provider records the provider being evaluated and the
+  one whose evaluation requested it, forming the dynamic resolution chain.
+  Scopes derived from it by Fork, Reset, ResetTypes and Without keep the chain.
+- An initializer records the frame of the chain evaluating it. Requesting an
+  initializer evaluated by a frame of the requesting chain is a re-entrant
+  resolution, reported as a DependencyLoopError with the dynamic path instead
+  of a hang.
+- A chain waiting on an initializer evaluated by another chain registers what
+  it waits on. If following the evaluating chains and what they wait on leads
+  back to the requesting chain, the chains wait on each other; the requesting
+  one reports a DependencyLoopError with the path through all chains.
+- Detection costs nothing while initializers are not contended: frames are
+  allocated per provider evaluation, wait registration happens only when a
+  lock is held by someone else.
+- A scope handed to a goroutine started by a provider carries the chain of
+  that provider; resolving the provider's type there reports a loop even if
+  the provider would not have waited for the goroutine.
+`
+
+// _ResolvingFrame is a provider being evaluated by a resolution chain.
+type _ResolvingFrame struct {
+	Initializer *_Initializer
+	// Type is the type requested from Initializer
+	Type reflect.Type
+	// Parent is the frame whose evaluation requested Initializer, or nil
+	Parent *_ResolvingFrame
+}
+
+// waitingFrames maps the innermost frame of a chain waiting on an initializer
+// to the initializer.
+var waitingFrames sync.Map // *_ResolvingFrame -> *_Initializer
+
+// contains reports whether frame is f or an ancestor of f.
+func (f *_ResolvingFrame) contains(frame *_ResolvingFrame) bool {
+	for ; f != nil; f = f.Parent {
+		if f == frame {
+			return true
+		}
+	}
+	return false
+}
+
+// pathFrom returns the frames from ancestor down to f.
+func (f *_ResolvingFrame) pathFrom(ancestor *_ResolvingFrame) (ret []*_ResolvingFrame) {
+	for ; f != nil; f = f.Parent {
+		ret = append(ret, f)
+		if f == ancestor {
+			break
+		}
+	}
+	slices.Reverse(ret)
+	return
+}
+
+// reentrantLoop returns the loop of a chain ending with frame requesting i
+// while a frame of the chain evaluates i, or nil.
+func reentrantLoop(frame *_ResolvingFrame, i *_Initializer) *DependencyLoopError {
+	owner := i.owner.Load()
+	if owner == nil || !frame.contains(owner) {
+		return nil
+	}
+	return newResolutionLoopError(frame.pathFrom(owner))
+}
+
+// waitLoop returns the loop of chains waiting on each other, starting with the
+// chain ending with frame waiting on i, or nil.
+func waitLoop(frame *_ResolvingFrame, i *_Initializer) *DependencyLoopError {
+	var path []*_ResolvingFrame
+	for range maxWaitLoopChains {
+		owner := i.owner.Load()
+		if owner == nil {
+			// not evaluated by anyone
+			return nil
+		}
+		if frame.contains(owner) {
+			path = append(frame.pathFrom(owner), path...)
+			return newResolutionLoopError(path)
+		}
+		// find what the evaluating chain waits on
+		var waiter *_ResolvingFrame
+		var next *_Initializer
+		waitingFrames.Range(func(k, v any) bool {
+			if f := k.(*_ResolvingFrame); f.contains(owner) {
+				waiter = f
+				next = v.(*_Initializer)
+				return false
+			}
+			return true
+		})
+		if waiter == nil {
+			// the evaluating chain is making progress
+			return nil
+		}
+		path = append(path, waiter.pathFrom(owner)...)
+		i = next
+	}
+	return nil
+}
+
+const maxWaitLoopChains = 1024
+
+func newResolutionLoopError(path []*_ResolvingFrame) *DependencyLoopError {
+	ret := new(DependencyLoopError)
+	for _, frame := range path {
+		ret.Cycle = append(ret.Cycle, frame.Type)
+		ret.Sources = append(ret.Sources, definitionSource(frame.Initializer.Def, false))
+	}
+	return ret
+}
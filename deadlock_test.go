@@ -0,0 +1,200 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReentrantResolutionSelf(t *testing.T) {
+	type Foo int
+	scope := New(func(scope Scope) Foo {
+		return Get[Foo](scope) + 1
+	})
+	err := recoverError(t, func() {
+		Get[Foo](scope)
+	})
+	if !errors.Is(err, ErrDependencyLoop) {
+		t.Fatalf("got %v", err)
+	}
+	var loopErr *DependencyLoopError
+	if !errors.As(err, &loopErr) {
+		t.Fatalf("got %T", err)
+	}
+	if !slices.Equal(loopErr.Cycle, []reflect.Type{reflect.TypeFor[Foo]()}) {
+		t.Fatalf("got %v", loopErr.Cycle)
+	}
+	if len(loopErr.Sources) != 1 {
+		t.Fatalf("got %v", loopErr.Sources)
+	}
+}
+
+func TestReentrantResolutionChain(t *testing.T) {
+	type Foo int
+	type Bar int
+	type Baz int
+	scope := New(
+		func(bar Bar) Foo {
+			return Foo(bar)
+		},
+		func(scope Scope) Bar {
+			return Bar(Get[Baz](scope))
+		},
+		func(foo Foo) Baz {
+			return Baz(foo)
+		},
+	)
+	err := recoverError(t, func() {
+		Get[Foo](scope)
+	})
+	var loopErr *DependencyLoopError
+	if !errors.As(err, &loopErr) {
+		t.Fatalf("got %v", err)
+	}
+	if !slices.Equal(loopErr.Cycle, []reflect.Type{
+		reflect.TypeFor[Foo](),
+		reflect.TypeFor[Bar](),
+		reflect.TypeFor[Baz](),
+	}) {
+		t.Fatalf("got %v", loopErr.Cycle)
+	}
+
+	// not cached as a failure
+	err = recoverError(t, func() {
+		Get[Foo](scope)
+	})
+	if !errors.Is(err, ErrDependencyLoop) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestReentrantResolutionInject(t *testing.T) {
+	type Foo int
+	type Deps struct {
+		Foo Inject[Foo] `dscope:"."`
+	}
+	scope := New(func(scope Scope) Foo {
+		var deps Deps
+		scope.InjectStruct(&deps)
+		return deps.Foo() + 1
+	})
+	err := recoverError(t, func() {
+		Get[Foo](scope)
+	})
+	if !errors.Is(err, ErrDependencyLoop) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestReentrantResolutionNotLoop(t *testing.T) {
+	type Foo int
+	type Bar int
+	scope := New(
+		func(scope Scope) Foo {
+			// resolving another type is fine
+			return Foo(Get[Bar](scope))
+		},
+		func() Bar {
+			return 42
+		},
+	)
+	if foo := Get[Foo](scope); foo != 42 {
+		t.Fatalf("got %v", foo)
+	}
+
+	// the scope handed to a provider is usable after it returned
+	type Baz int
+	var saved Scope
+	scope = New(func(scope Scope) Baz {
+		saved = scope
+		return 1
+	})
+	Get[Baz](scope)
+	if baz := Get[Baz](saved); baz != 1 {
+		t.Fatalf("got %v", baz)
+	}
+}
+
+func TestReentrantResolutionAcrossGoroutines(t *testing.T) {
+	type Foo int
+	type Bar int
+	// wait until both providers are being evaluated
+	var fooStarted, barStarted sync.Once
+	fooCh := make(chan struct{})
+	barCh := make(chan struct{})
+	scope := New(
+		func(scope Scope) Foo {
+			fooStarted.Do(func() {
+				close(fooCh)
+			})
+			<-barCh
+			return Foo(Get[Bar](scope))
+		},
+		func(scope Scope) Bar {
+			barStarted.Do(func() {
+				close(barCh)
+			})
+			<-fooCh
+			return Bar(Get[Foo](scope))
+		},
+	)
+
+	errs := make(chan error, 2)
+	for _, get := range []func(){
+		func() { Get[Foo](scope) },
+		func() { Get[Bar](scope) },
+	} {
+		go func() {
+			defer func() {
+				err, _ := recover().(error)
+				errs <- err
+			}()
+			get()
+		}()
+	}
+
+	loops := 0
+	for range 2 {
+		select {
+		case err := <-errs:
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, ErrDependencyLoop) {
+				t.Fatalf("got %v", err)
+			}
+			var loopErr *DependencyLoopError
+			if !errors.As(err, &loopErr) {
+				t.Fatalf("got %T", err)
+			}
+			if len(loopErr.Cycle) != 2 {
+				t.Fatalf("got %v", loopErr.Cycle)
+			}
+			loops++
+		case <-time.After(time.Second * 10):
+			t.Fatal("deadlock")
+		}
+	}
+	if loops == 0 {
+		t.Fatal("should report loop")
+	}
+}
+
+func BenchmarkReentrantResolutionFrames(b *testing.B) {
+	type Foo int
+	type Bar int
+	scope := New(
+		func(bar Bar) Foo {
+			return Foo(bar)
+		},
+		func() Bar {
+			return 42
+		},
+	)
+	for b.Loop() {
+		Get[Foo](scope.Reset())
+	}
+}
@@ -0,0 +1,217 @@
+package dscope
+
+import (
+	"reflect"
+)
+
+// _DeclaredProvider is a function definition whose declared signature differs
+// from the Go type of the function that computes its values. DefType declares
+// the dependencies and outputs used for analysis and cache keys; Func must
+// return values of the same output types in the same order, and may resolve
+// its own parameters, such as Scope, which are not declared as dependencies.
+type _DeclaredProvider struct {
+	DefType reflect.Type
+	Func    any
+}
+
+// _DefFlags are set on definitions by definition markers such as ResetExempt.
+type _DefFlags uint8
+
+const (
+	// defResetExempt keeps the initializer across Scope.Reset unless a
+	// dependency is reset.
+	defResetExempt _DefFlags = 1 << iota
+	// defOverride permits redefining an inherited type in strict scopes.
+	defOverride
+	// defDefault applies the definition only if its types have no definitions.
+	defDefault
+	// defConditional marks definitions of When analyzed by Scope.Validate
+	// without evaluating the predicate.
+	defConditional
+)
+
+// _Annotated is a definition carrying flags set by definition markers.
+type _Annotated struct {
+	Def   any
+	Flags _DefFlags
+}
+
+// annotate sets flags on def. Flags set on modules and conditional
+// definitions apply to every definition they contain.
+func annotate(def any, flags _DefFlags) any {
+	switch def := def.(type) {
+	case nil:
+		panic(&BadArgumentError{
+			Reason: "nil definition",
+		})
+	case _Annotated:
+		def.Flags |= flags
+		return def
+	case _Conditional:
+		defs := make([]any, 0, len(def.Defs))
+		for _, d := range def.Defs {
+			defs = append(defs, annotate(d, flags))
+		}
+		def.Defs = defs
+		return def
+	case Resolver:
+		panic(&BadArgumentError{
+			Reason: "cannot mark a resolver as a definition",
+		})
+	}
+	return _Annotated{
+		Def:   def,
+		Flags: flags,
+	}
+}
+
+// expandAnnotations expands annotated modules into annotated method
+// definitions, configured from scope, and converts annotated dynamic
+// providers. It also returns the modules configured from scope. validation is
+// passed to methods.
+func expandAnnotations(scope Scope, defs []any, validation *_Validation) ([]any, []*_ConfiguredModule) {
+	found := false
+	for _, def := range defs {
+		if annotated, ok := def.(_Annotated); ok {
+			switch annotated.Def.(type) {
+			case isModule, DynamicProvider:
+				found = true
+			}
+		}
+	}
+	if !found {
+		return defs, nil
+	}
+	// build a new slice to avoid modifying the caller's slice
+	ret := make([]any, 0, len(defs))
+	var modules []*_ConfiguredModule
+	for _, def := range defs {
+		annotated, ok := def.(_Annotated)
+		if !ok {
+			ret = append(ret, def)
+			continue
+		}
+		switch inner := annotated.Def.(type) {
+		case isModule:
+			discovered, configured := methods([]any{inner}, scope.strict, &scope, validation)
+			ret = append(ret, annotateDefinitions(discovered, annotated.Flags)...)
+			for _, module := range configured {
+				module.Flags = annotated.Flags
+				module.Defs = annotateDefinitions(module.Defs, annotated.Flags)
+				modules = append(modules, module)
+			}
+		case DynamicProvider:
+			annotated.Def = inner.definition()
+			ret = append(ret, annotated)
+		default:
+			ret = append(ret, annotated)
+		}
+	}
+	return ret, modules
+}
+
+// annotateDefinitions returns defs with flags set, or defs if flags is zero.
+func annotateDefinitions(defs []any, flags _DefFlags) []any {
+	if flags == 0 {
+		return defs
+	}
+	ret := make([]any, 0, len(defs))
+	for _, def := range defs {
+		ret = append(ret, _Annotated{
+			Def:   def,
+			Flags: flags,
+		})
+	}
+	return ret
+}
+
+// definitionFlags returns the flags set on def by definition markers.
+func definitionFlags(def any) _DefFlags {
+	if annotated, ok := def.(_Annotated); ok {
+		return annotated.Flags
+	}
+	return 0
+}
+
+// definitionType returns the type identifying def in dependency analysis and
+// cache keys.
+func definitionType(def any) reflect.Type {
+	if annotated, ok := def.(_Annotated); ok {
+		def = annotated.Def
+	}
+	switch def := def.(type) {
+	case _DeclaredProvider:
+		return def.DefType
+	case _ModuleDefinition:
+		return reflect.TypeOf(def.Def)
+	}
+	return reflect.TypeOf(def)
+}
+
+// definitionFunc returns the value an initializer evaluates for def.
+func definitionFunc(def any) any {
+	if annotated, ok := def.(_Annotated); ok {
+		def = annotated.Def
+	}
+	switch def := def.(type) {
+	case _DeclaredProvider:
+		return def.Func
+	case _ModuleDefinition:
+		return def.Func
+	}
+	return def
+}
+
+// definitionTypeID returns the type ID identifying t as a dependency or output
+// of def, which is a private type ID if t is private to the module of def.
+func definitionTypeID(def any, t reflect.Type) _TypeID {
+	if module := definitionModule(def); module != nil {
+		if id, ok := module.Private[t]; ok {
+			return id
+		}
+	}
+	return getTypeID(t)
+}
+
+// definitionModule returns the info of the module def is a method of, or nil
+// if the module is not configured by directives.
+func definitionModule(def any) *_ModuleInfo {
+	if annotated, ok := def.(_Annotated); ok {
+		def = annotated.Def
+	}
+	if def, ok := def.(_ModuleDefinition); ok {
+		return def.Module
+	}
+	return nil
+}
+
+// definitionInputTypes returns the dependency types of a function definition.
+// Other definitions have none.
+func definitionInputTypes(def any) (ret []reflect.Type) {
+	defType := definitionType(def)
+	if defType == nil || defType.Kind() != reflect.Func {
+		return nil
+	}
+	for i := range defType.NumIn() {
+		ret = append(ret, defType.In(i))
+	}
+	return
+}
+
+// definitionOutputTypes returns the types provided by a function or pointer
+// definition. Invalid definitions provide nothing.
+func definitionOutputTypes(def any) (ret []reflect.Type) {
+	defType := definitionType(def)
+	if defType == nil {
+		return nil
+	}
+	switch defType.Kind() {
+	case reflect.Func:
+		for i := range defType.NumOut() {
+			ret = append(ret, defType.Out(i))
+		}
+	case reflect.Pointer:
+		ret = append(ret, defType.Elem())
+	}
+	return
+}
@@ -3,9 +3,9 @@ package dscope
 import (
 	"crypto/sha256"
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"sync"
 )
 
@@ -19,6 +19,10 @@ type _TypeInfo struct {
 	TypeID       _TypeID
 	Position     int
 	Dependencies []_TypeID
+	// ResetExempt keeps the initializer across Scope.Reset unless a dependency is reset.
+	ResetExempt bool
+	// Module is the module configured by directives that defines the value, if any.
+	Module *_ModuleInfo
 }
 
 // _TypeID is a unique identifier for a reflect.Type.
@@ -45,6 +49,16 @@ type Scope struct {
 	signature _Hash
 	// forkFuncKey is a cache key representing the specific Fork operation that created this scope.
 	forkFuncKey _Hash
+	// resolvers supplies definitions for types without explicit definitions. Nil if none registered.
+	resolvers *_Resolvers
+	// strict rejects redefinitions of inherited types not marked with Override.
+	strict bool
+	// resolving is the innermost provider being evaluated by the resolution
+	// that handed out this scope. Nil outside providers.
+	resolving *_ResolvingFrame
+	// modules lists the configured modules whose methods are discovered again
+	// when a child Fork redefines a configuration type. Nil if none.
+	modules *_ConfiguredModules
 }
 
 // Universe is the empty root scope.
@@ -75,17 +89,104 @@ func (scope Scope) Fork(
 	defs ...any,
 ) Scope {
 
+	var resolvers *_Resolvers
+	var modules []*_ConfiguredModule
+	plain := scope.resolvers == nil && plainDefinitions(defs)
+	if !plain {
+		// the clone keeps the caller's variadic slice from escaping on the plain path
+		defs, resolvers, modules = prepareDefinitions(scope, slices.Clone(defs), nil)
+	}
+
+	// sorting defs may reduce memory consumption if there're calls with same defs but different order
+	// but sorting will increase heap allocations, causing performance drop
+
+	// Calculate cache key for this Fork operation.
+	// Key is based on parent signature and the types and flags of new definitions.
+	// Hashing types is sufficient as only one definition instance per type is effectively used.
+	h := sha256.New() // use cryptographic hash to avoid collision
+	h.Write(scope.signature[:])
+	buf := make([]byte, 0, len(defs)*8)
+	for _, def := range defs {
+		if plain {
+			// no flags and no modules
+			buf = binary.NativeEndian.AppendUint64(buf, uint64(getTypeID(reflect.TypeOf(def))))
+			continue
+		}
+		id := getTypeID(definitionType(def))
+		// flags occupy the high bits, which type IDs never reach
+		buf = binary.NativeEndian.AppendUint64(buf, uint64(id)|uint64(definitionFlags(def))<<48)
+		if module := definitionModule(def); module != nil {
+			// distinct from definition words and the strict word
+			buf = binary.NativeEndian.AppendUint64(buf, uint64(module.ID)|1<<62)
+		}
+	}
+	if scope.strict {
+		// distinct from any definition word, whose flags never reach bit 63
+		buf = binary.NativeEndian.AppendUint64(buf, 1<<63)
+	}
+	// h.Write (from sha256.New()) is not expected to return an error,
+	// but check is included for robustness against potential future changes
+	// or different hash.Hash implementations.
+	if _, err := h.Write(buf); err != nil {
+		panic(fmt.Errorf("unexpected error during hash calculation in Scope.Fork: %w", err))
+	}
+	var key _Hash
+	h.Sum(key[:0])
+
+	// Check cache
+	v, ok := forkers.Load(key)
+	if !ok {
+		// Cache miss, create and cache forker
+		forker := newForker(scope, defs, key)
+		v, _ = forkers.LoadOrStore(key, forker)
+	}
+
+	ret := v.(*_Forker).Fork(scope, defs)
+	ret.resolvers = resolvers
+	ret.strict = scope.strict
+	ret.resolving = scope.resolving
+	ret.modules = scope.modules.append(modules)
+	if inherited := len(scope.modules.list()); inherited > 0 {
+		ret = ret.rediscoverModules(inherited, defs)
+	}
+	return ret
+}
+
+// plainDefinitions reports whether defs are all provider functions or
+// pointers, which prepareDefinitions returns unchanged if the scope has no
+// resolvers.
+func plainDefinitions(defs []any) bool {
+	for _, def := range defs {
+		switch def.(type) {
+		case nil, _Conditional, _Annotated, _DeclaredProvider, _ModuleDefinition,
+			DynamicProvider, Resolver, isModule:
+			return false
+		}
+	}
+	return true
+}
+
+// prepareDefinitions expands conditional, annotated, module, dynamic and
+// resolved definitions in defs, returning the definitions to analyze, the
+// resolvers of the child scope and the modules configured from scope. If
+// validation is not nil, definitions are prepared statically for it: predicates
+// and module configuration fields are recorded as its requirements instead of
+// being resolved.
+func prepareDefinitions(scope Scope, defs []any, validation *_Validation) ([]any, *_Resolvers, []*_ConfiguredModule) {
+
 	// Validate before reflection and cache-key generation so malformed public
 	// input cannot leak implementation-specific panics.
 	for _, def := range defs {
 		if def == nil {
-			panic(errors.Join(
-				fmt.Errorf("nil definition"),
-				ErrBadArgument,
-			))
+			panic(&BadArgumentError{
+				Reason: "nil definition",
+			})
 		}
 	}
 
+	defs = expandConditionals(scope, defs, validation)
+	defs, modules := expandAnnotations(scope, defs, validation)
+
 	// handle modules
 	var moduleObjects []any
 	for _, def := range defs {
@@ -103,42 +204,58 @@ func (scope Scope) Fork(
 				newDefs = append(newDefs, def)
 			}
 		}
-		defs = append(newDefs, Methods(moduleObjects...)...)
+		discovered, configured := methods(moduleObjects, scope.strict, &scope, validation)
+		defs = append(newDefs, discovered...)
+		modules = append(modules, configured...)
 	}
 
-	// sorting defs may reduce memory consumption if there're calls with same defs but different order
-	// but sorting will increase heap allocations, causing performance drop
+	// handle dynamic providers
+	cloned := false
+	for i, def := range defs {
+		provider, ok := def.(DynamicProvider)
+		if !ok {
+			continue
+		}
+		if !cloned {
+			// avoid modifying the caller's slice
+			defs = slices.Clone(defs)
+			cloned = true
+		}
+		defs[i] = provider.definition()
+	}
 
-	// Calculate cache key for this Fork operation.
-	// Key is based on parent signature and the types of new definitions.
-	// Hashing types is sufficient as only one definition instance per type is effectively used.
-	h := sha256.New() // use cryptographic hash to avoid collision
-	h.Write(scope.signature[:])
-	buf := make([]byte, 0, len(defs)*8)
+	// handle resolvers
+	resolvers := scope.resolvers
+	var newResolverFuncs []Resolver
 	for _, def := range defs {
-		id := getTypeID(reflect.TypeOf(def))
-		buf = binary.NativeEndian.AppendUint64(buf, uint64(id))
+		if resolver, ok := def.(Resolver); ok {
+			if resolver == nil {
+				panic(&BadArgumentError{
+					Reason: "nil resolver",
+				})
+			}
+			newResolverFuncs = append(newResolverFuncs, resolver)
+		}
 	}
-	// h.Write (from sha256.New()) is not expected to return an error,
-	// but check is included for robustness against potential future changes
-	// or different hash.Hash implementations.
-	if _, err := h.Write(buf); err != nil {
-		panic(fmt.Errorf("unexpected error during hash calculation in Scope.Fork: %w", err))
+	if len(newResolverFuncs) > 0 {
+		newDefs := make([]any, 0, len(defs))
+		for _, def := range defs {
+			if _, ok := def.(Resolver); !ok {
+				newDefs = append(newDefs, def)
+			}
+		}
+		defs = newDefs
+		// resolvers registered later take precedence
+		resolvers = newResolvers(append(newResolverFuncs, resolvers.funcs()...), resolvers.excluded())
+	} else {
+		// lazily resolved values are not inherited
+		resolvers = resolvers.reset()
 	}
-	var key _Hash
-	h.Sum(key[:0])
-
-	// Check cache
-	v, ok := forkers.Load(key)
-	if ok {
-		return v.(*_Forker).Fork(scope, defs)
+	if resolvers != nil {
+		defs = resolveDefinitions(scope, defs, resolvers)
 	}
 
-	// Cache miss, create and cache forker
-	forker := newForker(scope, defs, key)
-	v, _ = forkers.LoadOrStore(key, forker)
-
-	return v.(*_Forker).Fork(scope, defs)
+	return defs, resolvers, modules
 }
 
 const TheoryOfScopeReset = `
@@ -151,6 +268,8 @@ dscope reset theory:
   most once within the reset scope.
 - Appending to (Forking from) a reset scope materialises the layer into a flat
   stack, preserving correct dependency-analysis invariants.
+- Definitions marked ResetExempt keep their initializers while none of their
+  dependencies is reset (see TheoryOfResetExemption).
 `
 
 // Reset returns a new Scope in which every value will be recomputed the next
@@ -173,6 +292,10 @@ func (scope Scope) Reset() Scope {
 		},
 		signature:   scope.signature,
 		forkFuncKey: scope.forkFuncKey,
+		resolvers:   scope.resolvers.reset(),
+		strict:      scope.strict,
+		resolving:   scope.resolving,
+		modules:     scope.modules,
 	}
 }
 
@@ -191,21 +314,19 @@ func (scope Scope) Assign(objects ...any) {
 	for _, o := range objects {
 		v := reflect.ValueOf(o)
 		if v.Kind() != reflect.Pointer {
-			panic(errors.Join(
-				fmt.Errorf("%T is not a pointer", o),
-				ErrBadArgument,
-			))
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("%T is not a pointer", o),
+			})
 		}
 		if v.IsNil() {
-			panic(errors.Join(
-				fmt.Errorf("cannot assign to a nil pointer target of type %v", v.Type()),
-				ErrBadArgument,
-			))
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("cannot assign to a nil pointer target of type %v", v.Type()),
+			})
 		}
 		t := v.Type().Elem()
 		value, ok := scope.Get(t)
 		if !ok {
-			throwErrDependencyNotFound(t)
+			throwErrDependencyNotFound(scope, t)
 		}
 		v.Elem().Set(value)
 	}
@@ -215,10 +336,9 @@ func (scope Scope) Assign(objects ...any) {
 // It panics with ErrBadArgument if ptr is nil.
 func Assign[T any](scope Scope, ptr *T) {
 	if ptr == nil {
-		panic(errors.Join(
-			fmt.Errorf("cannot assign to a nil pointer target of type %T", ptr),
-			ErrBadArgument,
-		))
+		panic(&BadArgumentError{
+			Reason: fmt.Sprintf("cannot assign to a nil pointer target of type %T", ptr),
+		})
 	}
 	*ptr = Get[T](scope)
 }
@@ -245,11 +365,23 @@ func (scope Scope) get(id _TypeID) (
 		// generic Get[Reset] succeed. The method value yields an unnamed
 		// func() Scope type, which is not identical to Reset.
 		return reflect.ValueOf(scope.Reset).Convert(reflect.TypeFor[Reset]()), true
+	case scopeTypeID:
+		return reflect.ValueOf(scope), true
+	case callTypeID:
+		// Convert to the named Call type so that type assertions and
+		// generic Get[Call] succeed.
+		return reflect.ValueOf(scope.Call).Convert(reflect.TypeFor[Call]()), true
 	}
 
 	value, ok := scope.values.Load(id)
 	if !ok {
-		return ret, false
+		if scope.resolvers == nil {
+			return ret, false
+		}
+		value, ok = scope.resolvers.load(scope, id)
+		if !ok {
+			return ret, false
+		}
 	}
 
 	return value.initializer.get(scope, value.typeInfo.Position), true
@@ -277,25 +409,22 @@ dscope invocation theory:
 
 func validateCallableValue(fnValue reflect.Value) reflect.Type {
 	if !fnValue.IsValid() {
-		panic(errors.Join(
-			fmt.Errorf("nil function provided"),
-			ErrBadArgument,
-		))
+		panic(&BadArgumentError{
+			Reason: "nil function provided",
+		})
 	}
 
 	fnType := fnValue.Type()
 	if fnType.Kind() != reflect.Func {
-		panic(errors.Join(
-			fmt.Errorf("%v is not a function", fnType),
-			ErrBadArgument,
-		))
+		panic(&BadArgumentError{
+			Reason: fmt.Sprintf("%v is not a function", fnType),
+		})
 	}
 
 	if fnValue.IsNil() {
-		panic(errors.Join(
-			fmt.Errorf("%v nil function provided", fnType),
-			ErrBadArgument,
-		))
+		panic(&BadArgumentError{
+			Reason: fmt.Sprintf("%v nil function provided", fnType),
+		})
 	}
 
 	return fnType
@@ -307,7 +436,7 @@ func Get[T any](scope Scope) (o T) {
 	typ := reflect.TypeFor[T]()
 	value, ok := scope.Get(typ)
 	if !ok {
-		throwErrDependencyNotFound(typ)
+		throwErrDependencyNotFound(scope, typ)
 	}
 	if typ.Kind() == reflect.Interface && value.IsNil() {
 		return o
@@ -348,7 +477,7 @@ func (scope Scope) getArgsSlow(fnType reflect.Type, args []reflect.Value) int {
 			var ok bool
 			args[i], ok = scope.get(ids[i])
 			if !ok {
-				throwErrDependencyNotFound(typeIDToType(ids[i]))
+				throwErrDependencyNotFound(scope, typeIDToType(ids[i]))
 			}
 		}
 		return numIn
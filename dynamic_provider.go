@@ -0,0 +1,84 @@
+package dscope
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+const TheoryOfDynamicProviders = `
+dscope dynamic provider theory:
+- A DynamicProvider is a definition computed at runtime: it declares its input
+  types, its output types and a reflection-level body.
+- Fork turns it into a function of the declared signature before analysis, so
+  it is treated exactly like a function definition: inputs are dependencies,
+  outputs have positions and share one initializer, and reset analysis applies.
+- The Fork cache key depends on the declared signature, never on the Go type of
+  the DynamicProvider wrapper.
+- The body must return one value per declared output, each assignable to the
+  declared type; otherwise resolution fails with ErrBadDefinition.
+`
+
+// DynamicProvider is a definition with explicitly declared input and output
+// types. Func receives values of the In types and returns values of the Out
+// types.
+type DynamicProvider struct {
+	In   []reflect.Type
+	Out  []reflect.Type
+	Func func(args []reflect.Value) []reflect.Value
+}
+
+// definition returns a function definition with the declared signature.
+func (p DynamicProvider) definition() any {
+	if p.Func == nil {
+		panic(&BadArgumentError{
+			Reason: "nil function in dynamic provider",
+		})
+	}
+	if len(p.Out) == 0 {
+		panic(&BadArgumentError{
+			Reason: "dynamic provider returns nothing",
+		})
+	}
+	for _, types := range [][]reflect.Type{p.In, p.Out} {
+		for _, t := range types {
+			if t == nil {
+				panic(&BadArgumentError{
+					Reason: "nil type in dynamic provider signature",
+				})
+			}
+		}
+	}
+
+	out := p.Out
+	fnType := reflect.FuncOf(p.In, out, false)
+	fn := p.Func
+	return reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+		rets := fn(args)
+		if len(rets) != len(out) {
+			panic(errors.Join(
+				fmt.Errorf("dynamic provider %v returned %d values", fnType, len(rets)),
+				ErrBadDefinition,
+			))
+		}
+		for i, ret := range rets {
+			if !ret.IsValid() {
+				rets[i] = reflect.Zero(out[i])
+				continue
+			}
+			if ret.Type() == out[i] {
+				continue
+			}
+			if !ret.Type().AssignableTo(out[i]) {
+				panic(errors.Join(
+					fmt.Errorf("dynamic provider %v returned %v at position %d", fnType, ret.Type(), i),
+					ErrBadDefinition,
+				))
+			}
+			value := reflect.New(out[i]).Elem()
+			value.Set(ret)
+			rets[i] = value
+		}
+		return rets
+	}).Interface()
+}
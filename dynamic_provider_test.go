@@ -0,0 +1,154 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDynamicProvider(t *testing.T) {
+	var calls int
+	provider := DynamicProvider{
+		In:  []reflect.Type{reflect.TypeFor[int]()},
+		Out: []reflect.Type{reflect.TypeFor[string](), reflect.TypeFor[int64]()},
+		Func: func(args []reflect.Value) []reflect.Value {
+			calls++
+			i := args[0].Int()
+			return []reflect.Value{
+				reflect.ValueOf(strings.Repeat("a", int(i))),
+				reflect.ValueOf(i * 2),
+			}
+		},
+	}
+	scope := New(Provide(3), provider)
+	if s := Get[string](scope); s != "aaa" {
+		t.Fatalf("got %s", s)
+	}
+	if i := Get[int64](scope); i != 6 {
+		t.Fatalf("got %d", i)
+	}
+	if calls != 1 {
+		t.Fatalf("outputs should share one initializer, got %d calls", calls)
+	}
+
+	value, ok := scope.values.Load(getTypeID(reflect.TypeFor[int64]()))
+	if !ok {
+		t.Fatal()
+	}
+	if value.typeInfo.Position != 1 {
+		t.Fatalf("got position %d", value.typeInfo.Position)
+	}
+	if value.typeInfo.DefType != reflect.TypeFor[func(int) (string, int64)]() {
+		t.Fatalf("got %v", value.typeInfo.DefType)
+	}
+
+	// reset analysis
+	child := scope.Fork(Provide(1))
+	if s := Get[string](child); s != "a" {
+		t.Fatalf("got %s", s)
+	}
+}
+
+func TestDynamicProviderCacheKey(t *testing.T) {
+	a := New(DynamicProvider{
+		Out: []reflect.Type{reflect.TypeFor[int]()},
+		Func: func([]reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.ValueOf(1)}
+		},
+	})
+	b := New(DynamicProvider{
+		Out: []reflect.Type{reflect.TypeFor[string]()},
+		Func: func([]reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.ValueOf("foo")}
+		},
+	})
+	if a.forkFuncKey == b.forkFuncKey {
+		t.Fatal("cache key should depend on declared signature")
+	}
+	if Get[int](a) != 1 || Get[string](b) != "foo" {
+		t.Fatal()
+	}
+}
+
+func TestDynamicProviderDependencyNotFound(t *testing.T) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		if err, ok := p.(error); !ok || !errors.Is(err, ErrDependencyNotFound) {
+			t.Fatalf("got %v", p)
+		}
+	}()
+	New(DynamicProvider{
+		In:  []reflect.Type{reflect.TypeFor[int]()},
+		Out: []reflect.Type{reflect.TypeFor[string]()},
+		Func: func([]reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.ValueOf("foo")}
+		},
+	})
+}
+
+func TestDynamicProviderBadResults(t *testing.T) {
+	scope := New(DynamicProvider{
+		Out: []reflect.Type{reflect.TypeFor[string]()},
+		Func: func([]reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.ValueOf(42)}
+		},
+	})
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		if err, ok := p.(error); !ok || !errors.Is(err, ErrBadDefinition) {
+			t.Fatalf("got %v", p)
+		}
+	}()
+	Get[string](scope)
+}
+
+func TestDynamicProviderInterfaceOutput(t *testing.T) {
+	scope := New(DynamicProvider{
+		Out: []reflect.Type{reflect.TypeFor[error](), reflect.TypeFor[any]()},
+		Func: func([]reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.ValueOf(errors.New("foo")), {}}
+		},
+	})
+	if err := Get[error](scope); err == nil || err.Error() != "foo" {
+		t.Fatalf("got %v", err)
+	}
+	if v := Get[any](scope); v != nil {
+		t.Fatalf("got %v", v)
+	}
+}
+
+func TestDynamicProviderBadArgument(t *testing.T) {
+	for _, provider := range []DynamicProvider{
+		{
+			Out: []reflect.Type{reflect.TypeFor[int]()},
+		},
+		{
+			Func: func([]reflect.Value) []reflect.Value { return nil },
+		},
+		{
+			In:   []reflect.Type{nil},
+			Out:  []reflect.Type{reflect.TypeFor[int]()},
+			Func: func([]reflect.Value) []reflect.Value { return nil },
+		},
+	} {
+		func() {
+			defer func() {
+				p := recover()
+				if p == nil {
+					t.Fatal("should panic")
+				}
+				if err, ok := p.(error); !ok || !errors.Is(err, ErrBadArgument) {
+					t.Fatalf("got %v", p)
+				}
+			}()
+			New(provider)
+		}()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 )
 
 var ErrDependencyLoop = errors.New("dependency loop")
@@ -14,9 +15,211 @@ var ErrBadArgument = errors.New("bad argument")
 
 var ErrBadDefinition = errors.New("bad definition")
 
-func throwErrDependencyNotFound(typ reflect.Type) {
-	panic(errors.Join(
-		fmt.Errorf("no definition for %v", typ),
-		ErrDependencyNotFound,
-	))
+var ErrProviderFailed = errors.New("provider failed")
+
+// DependencyNotFoundError reports a type without definition. It matches
+// ErrDependencyNotFound.
+type DependencyNotFoundError struct {
+	// Type is the type without definition
+	Type reflect.Type
+	// RequiredBy is the type of the definition depending on Type, or nil if
+	// Type was requested directly
+	RequiredBy reflect.Type
+	// Source describes where the definition depending on Type came from
+	Source string
+	// Path lists the types leading to the definition depending on Type, each
+	// depending on the next; the last is provided by that definition
+	Path []reflect.Type
+	// Suggestions lists types that may have been meant, ranked
+	Suggestions []Suggestion
+}
+
+var _ error = new(DependencyNotFoundError)
+
+func (e *DependencyNotFoundError) Error() string {
+	buf := new(strings.Builder)
+	if e.RequiredBy != nil {
+		fmt.Fprintf(buf, "dependency not found in definition %v, no definition for %v", e.RequiredBy, e.Type)
+	} else {
+		fmt.Fprintf(buf, "no definition for %v", e.Type)
+	}
+	if e.Source != "" {
+		fmt.Fprintf(buf, ", required by %s", e.Source)
+	}
+	if len(e.Path) > 0 {
+		fmt.Fprintf(buf, "\npath: %s", formatTypePath(e.Path))
+	}
+	if len(e.Suggestions) > 0 {
+		buf.WriteString("\ndid you mean: ")
+		for i, suggestion := range e.Suggestions {
+			if i > 0 {
+				buf.WriteString("; ")
+			}
+			buf.WriteString(suggestion.String())
+		}
+	}
+	buf.WriteString("\n")
+	buf.WriteString(ErrDependencyNotFound.Error())
+	return buf.String()
+}
+
+func (e *DependencyNotFoundError) Is(target error) bool {
+	return target == ErrDependencyNotFound
+}
+
+// DependencyLoopError reports definitions depending on each other. It matches
+// ErrDependencyLoop.
+type DependencyLoopError struct {
+	// Cycle lists the types in the loop, each depending on the next, and the
+	// last depending on the first
+	Cycle []reflect.Type
+	// Sources describes where the definition of each type in Cycle came from
+	Sources []string
+}
+
+var _ error = new(DependencyLoopError)
+
+func (e *DependencyLoopError) Error() string {
+	buf := new(strings.Builder)
+	fmt.Fprintf(buf, "found dependency loop in definition of %v\n", e.Cycle[0])
+	buf.WriteString(ErrDependencyLoop.Error())
+	fmt.Fprintf(buf, "\npath: %s -> %v", formatTypePath(e.Cycle), e.Cycle[0])
+	if len(e.Sources) > 0 {
+		buf.WriteString("\ndefinitions: ")
+		for i, source := range e.Sources {
+			if i > 0 {
+				buf.WriteString("; ")
+			}
+			fmt.Fprintf(buf, "%v by %s", e.Cycle[i], source)
+		}
+	}
+	return buf.String()
+}
+
+func (e *DependencyLoopError) Is(target error) bool {
+	return target == ErrDependencyLoop
+}
+
+// DuplicateDefinitionError reports a type with more than one definition. It
+// matches ErrBadDefinition.
+type DuplicateDefinitionError struct {
+	Type reflect.Type
+	// Definitions describes where each definition of Type came from
+	Definitions []string
+}
+
+var _ error = new(DuplicateDefinitionError)
+
+func (e *DuplicateDefinitionError) Error() string {
+	buf := new(strings.Builder)
+	fmt.Fprintf(buf, "%v has multiple definitions", e.Type)
+	if len(e.Definitions) > 0 {
+		fmt.Fprintf(buf, ", by %s", strings.Join(e.Definitions, " and "))
+	}
+	buf.WriteString("\n")
+	buf.WriteString(ErrBadDefinition.Error())
+	return buf.String()
+}
+
+func (e *DuplicateDefinitionError) Is(target error) bool {
+	return target == ErrBadDefinition
+}
+
+// RedefinitionError reports a definition redefining an inherited type in a
+// strict scope without Override. It matches ErrBadDefinition.
+type RedefinitionError struct {
+	Type reflect.Type
+	// DefType is the type of the redefining definition
+	DefType reflect.Type
+	// Definition describes where the redefining definition came from
+	Definition string
+	// Inherited describes where the inherited definition came from
+	Inherited string
+}
+
+var _ error = new(RedefinitionError)
+
+func (e *RedefinitionError) Error() string {
+	return fmt.Sprintf("%v redefines inherited %v without Override, by %s, inherited from %s\n%s",
+		e.DefType, e.Type, e.Definition, e.Inherited, ErrBadDefinition.Error())
+}
+
+func (e *RedefinitionError) Is(target error) bool {
+	return target == ErrBadDefinition
+}
+
+// BadArgumentError reports an invalid argument passed to dscope. It matches
+// ErrBadArgument.
+type BadArgumentError struct {
+	Reason string
+}
+
+var _ error = new(BadArgumentError)
+
+func (e *BadArgumentError) Error() string {
+	return e.Reason + "\n" + ErrBadArgument.Error()
+}
+
+func (e *BadArgumentError) Is(target error) bool {
+	return target == ErrBadArgument
+}
+
+// ProviderError reports a panic in a provider. It matches ErrProviderFailed,
+// and unwraps to the panic value if it is an error.
+type ProviderError struct {
+	// DefType is the type of the failing definition
+	DefType reflect.Type
+	// Chain lists the types being resolved when the provider failed, each
+	// depending on the next; the last is provided by the failing definition
+	Chain []reflect.Type
+	// Value is the original panic value
+	Value any
+	// Stack is the stack trace of the original panic
+	Stack []byte
+}
+
+var _ error = new(ProviderError)
+
+func (e *ProviderError) Error() string {
+	buf := new(strings.Builder)
+	fmt.Fprintf(buf, "provider %v failed: %v\n", e.DefType, e.Value)
+	buf.WriteString(ErrProviderFailed.Error())
+	buf.WriteString("\nchain: ")
+	for i, t := range e.Chain {
+		if i > 0 {
+			buf.WriteString(" <- ")
+		}
+		buf.WriteString(t.String())
+	}
+	return buf.String()
+}
+
+func (e *ProviderError) Is(target error) bool {
+	return target == ErrProviderFailed
+}
+
+func (e *ProviderError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// formatTypePath joins types with arrows.
+func formatTypePath(types []reflect.Type) string {
+	buf := new(strings.Builder)
+	for i, t := range types {
+		if i > 0 {
+			buf.WriteString(" -> ")
+		}
+		buf.WriteString(t.String())
+	}
+	return buf.String()
+}
+
+func throwErrDependencyNotFound(scope Scope, typ reflect.Type) {
+	panic(&DependencyNotFoundError{
+		Type:        typ,
+		Suggestions: suggest(scope, typ),
+	})
 }
@@ -0,0 +1,133 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDependencyNotFoundError(t *testing.T) {
+	type A int
+	type B int
+	err := recoverError(t, func() {
+		New(
+			func(b B) A { return A(b) },
+			func(s string) B { return 1 },
+		)
+	})
+	var notFound *DependencyNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("got %T", err)
+	}
+	if !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatal()
+	}
+	if notFound.Type != reflect.TypeFor[string]() {
+		t.Fatalf("got %v", notFound.Type)
+	}
+	if notFound.RequiredBy != reflect.TypeFor[func(string) B]() {
+		t.Fatalf("got %v", notFound.RequiredBy)
+	}
+	if len(notFound.Path) == 0 || notFound.Path[len(notFound.Path)-1] != reflect.TypeFor[B]() {
+		t.Fatalf("got %v", notFound.Path)
+	}
+
+	// runtime resolution
+	err = recoverError(t, func() {
+		Get[string](New())
+	})
+	if !errors.As(err, &notFound) {
+		t.Fatalf("got %T", err)
+	}
+	if notFound.Type != reflect.TypeFor[string]() || notFound.RequiredBy != nil {
+		t.Fatalf("got %+v", notFound)
+	}
+	if !strings.Contains(err.Error(), "no definition for string") {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestDependencyLoopError(t *testing.T) {
+	type A int
+	type B int
+	type C int
+	err := recoverError(t, func() {
+		New(
+			func(b B) A { return 1 },
+			func(c C) B { return 1 },
+			func(a A) C { return 1 },
+		)
+	})
+	var loop *DependencyLoopError
+	if !errors.As(err, &loop) {
+		t.Fatalf("got %T", err)
+	}
+	if !errors.Is(err, ErrDependencyLoop) {
+		t.Fatal()
+	}
+	if len(loop.Cycle) != 3 || len(loop.Sources) != 3 {
+		t.Fatalf("got %v", loop.Cycle)
+	}
+	types := map[reflect.Type]bool{}
+	for _, typ := range loop.Cycle {
+		types[typ] = true
+	}
+	if !types[reflect.TypeFor[A]()] || !types[reflect.TypeFor[B]()] || !types[reflect.TypeFor[C]()] {
+		t.Fatalf("got %v", loop.Cycle)
+	}
+}
+
+func TestDuplicateDefinitionError(t *testing.T) {
+	err := recoverError(t, func() {
+		New(
+			func() int { return 1 },
+			func() int { return 2 },
+		)
+	})
+	var duplicate *DuplicateDefinitionError
+	if !errors.As(err, &duplicate) {
+		t.Fatalf("got %T", err)
+	}
+	if !errors.Is(err, ErrBadDefinition) {
+		t.Fatal()
+	}
+	if duplicate.Type != reflect.TypeFor[int]() || len(duplicate.Definitions) != 2 {
+		t.Fatalf("got %+v", duplicate)
+	}
+}
+
+func TestRedefinitionError(t *testing.T) {
+	err := recoverError(t, func() {
+		New(func() int { return 1 }).Strict().Fork(func() int { return 2 })
+	})
+	var redefinition *RedefinitionError
+	if !errors.As(err, &redefinition) {
+		t.Fatalf("got %T", err)
+	}
+	if !errors.Is(err, ErrBadDefinition) {
+		t.Fatal()
+	}
+	if redefinition.Type != reflect.TypeFor[int]() ||
+		redefinition.DefType != reflect.TypeFor[func() int]() ||
+		!strings.Contains(redefinition.Definition, "errors_test.go") ||
+		!strings.Contains(redefinition.Inherited, "errors_test.go") {
+		t.Fatalf("got %+v", redefinition)
+	}
+}
+
+func TestBadArgumentError(t *testing.T) {
+	err := recoverError(t, func() {
+		New().Call(42)
+	})
+	var badArgument *BadArgumentError
+	if !errors.As(err, &badArgument) {
+		t.Fatalf("got %T", err)
+	}
+	if !errors.Is(err, ErrBadArgument) {
+		t.Fatal()
+	}
+	if badArgument.Reason != "int is not a function" {
+		t.Fatalf("got %v", badArgument.Reason)
+	}
+}
@@ -4,11 +4,9 @@ import (
 	"cmp"
 	"crypto/sha256"
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"reflect"
 	"slices"
-	"strings"
 )
 
 // _Forker pre-calculates the information required to efficiently create a new child scope.
@@ -43,44 +41,58 @@ func newForker(
 
 	// 1. Process Definitions: Create templates, store metadata, identify overrides.
 	newValuesTemplate := make([]_Value, 0, len(defs))
-	redefinedIDs := make(map[_TypeID]struct{})    // Set of overridden TypeIDs
-	newDefOutputIDs := make(map[_TypeID]struct{}) // Set of TypeIDs produced by new defs in this layer
+	redefinedIDs := make(map[_TypeID]struct{}) // Set of overridden TypeIDs
+	newDefOutputIDs := make(map[_TypeID]int)   // TypeIDs produced by new defs in this layer -> def index
 	defNumValues := make([]int, 0, len(defs))
 	defKinds := make([]reflect.Kind, 0, len(defs))
-	for _, def := range defs {
+	skipped := skippedDefaults(scope, defs)
+	for i, def := range defs {
 		if def == nil {
-			panic(errors.Join(
-				fmt.Errorf("nil definition"),
-				ErrBadArgument,
-			))
+			panic(&BadArgumentError{
+				Reason: "nil definition",
+			})
+		}
+
+		if skipped != nil && skipped[i] {
+			// Invalid kind: no values are created for this definition
+			defKinds = append(defKinds, reflect.Invalid)
+			defNumValues = append(defNumValues, 0)
+			continue
 		}
 
-		defType := reflect.TypeOf(def)
-		defValue := reflect.ValueOf(def)
+		defIndex := i
+		defType := definitionType(def)
+		defValue := reflect.ValueOf(definitionFunc(def))
+		flags := definitionFlags(def)
+		resetExempt := flags&defResetExempt != 0
+		module := definitionModule(def)
 		defKinds = append(defKinds, defType.Kind())
 
-		switch defType.Kind() {
-		case reflect.Func:
-			// Validate function
-			if defValue.IsNil() {
-				panic(errors.Join(
-					fmt.Errorf("%T nil function provided", def),
-					ErrBadArgument,
-				))
+		// markRedefined records an override of an inherited definition
+		markRedefined := func(id _TypeID, t reflect.Type) {
+			if _, ok := scope.values.Load(id); !ok {
+				return
 			}
-			if defType.NumOut() == 0 {
-				panic(errors.Join(
-					fmt.Errorf("%T returns nothing", def),
-					ErrBadArgument,
-				))
+			if scope.strict && flags&defOverride == 0 {
+				inherited, _ := scope.values.Load(id)
+				panic(redefinitionError(def, t, inherited))
 			}
+			redefinedIDs[id] = struct{}{} // Mark override
+		}
+
+		if err := definitionError(defType, defValue); err != nil {
+			panic(err)
+		}
+
+		switch defType.Kind() {
+		case reflect.Func:
 
 			// Extract Dependencies
 			numIn := defType.NumIn()
 			dependencies := make([]_TypeID, 0, numIn)
 			for i := range numIn {
 				inType := defType.In(i)
-				dependencies = append(dependencies, getTypeID(inType))
+				dependencies = append(dependencies, definitionTypeID(def, inType))
 			}
 
 			// Create Value Templates for Outputs
@@ -88,14 +100,17 @@ func newForker(
 			var numValues int
 			for i := range numOut {
 				t := defType.Out(i)
-				id := getTypeID(t)
+				id := definitionTypeID(def, t)
 
 				// Check for duplicate outputs within the new definitions slice
-				if _, ok := newDefOutputIDs[id]; ok {
-					panic(errors.Join(
-						fmt.Errorf("%v has multiple definitions", t),
-						ErrBadDefinition,
-					))
+				if prev, ok := newDefOutputIDs[id]; ok {
+					panic(&DuplicateDefinitionError{
+						Type: t,
+						Definitions: []string{
+							definitionSource(defs[prev], true),
+							definitionSource(def, true),
+						},
+					})
 				}
 
 				newValuesTemplate = append(newValuesTemplate, _Value{
@@ -104,53 +119,42 @@ func newForker(
 						DefType:      defType,
 						Position:     i,
 						Dependencies: dependencies,
+						ResetExempt:  resetExempt,
+						Module:       module,
 					},
 				})
 				numValues++
-				newDefOutputIDs[id] = struct{}{}
-				if _, ok := scope.values.Load(id); ok {
-					redefinedIDs[id] = struct{}{} // Mark override
-				}
+				newDefOutputIDs[id] = defIndex
+				markRedefined(id, t)
 			}
 			defNumValues = append(defNumValues, numValues)
 
 		case reflect.Pointer:
-			// Validate pointer
-			if defValue.IsNil() {
-				panic(errors.Join(
-					fmt.Errorf("%T nil pointer provided", def),
-					ErrBadArgument,
-				))
-			}
-
 			// Create Value Template
 			t := defType.Elem()
-			id := getTypeID(t)
-
-			if _, ok := newDefOutputIDs[id]; ok {
-				panic(errors.Join(
-					fmt.Errorf("%s has multiple definitions", t),
-					ErrBadDefinition,
-				))
+			id := definitionTypeID(def, t)
+
+			if prev, ok := newDefOutputIDs[id]; ok {
+				panic(&DuplicateDefinitionError{
+					Type: t,
+					Definitions: []string{
+						definitionSource(defs[prev], true),
+						definitionSource(def, true),
+					},
+				})
 			}
 
 			newValuesTemplate = append(newValuesTemplate, _Value{
 				typeInfo: &_TypeInfo{
-					TypeID:  id,
-					DefType: defType,
+					TypeID:      id,
+					DefType:     defType,
+					ResetExempt: resetExempt,
+					Module:      module,
 				},
 			})
-			newDefOutputIDs[id] = struct{}{}
-			if _, ok := scope.values.Load(id); ok {
-				redefinedIDs[id] = struct{}{} // Mark override
-			}
+			newDefOutputIDs[id] = defIndex
+			markRedefined(id, t)
 			defNumValues = append(defNumValues, 1)
-
-		default:
-			panic(errors.Join(
-				fmt.Errorf("%T is not a valid definition", def),
-				ErrBadArgument,
-			))
 		}
 	}
 
@@ -183,6 +187,11 @@ func newForker(
 	colors := make(map[_TypeID]int)      // For cycle detection
 	needsReset := make(map[_TypeID]bool) // Memoization for reset status
 
+	// errValues are the values whose sources the error of traverse describes.
+	// traverse does not capture defs, so callers' definition slices need not
+	// escape.
+	var errValues []_Value
+
 	var traverse func(value _Value, path []_TypeID) (reset bool, err error)
 	traverse = func(value _Value, path []_TypeID) (reset bool, err error) {
 		id := value.typeInfo.TypeID
@@ -192,20 +201,14 @@ func newForker(
 		switch color {
 
 		case 1: // Gray: Loop detected
-			return false, errors.Join(
-				fmt.Errorf("found dependency loop in definition %v", value.typeInfo.DefType),
-				ErrDependencyLoop,
-				func() error {
-					buf := new(strings.Builder)
-					for i, id := range path {
-						if i > 0 {
-							buf.WriteString(" -> ")
-						}
-						buf.WriteString(typeIDToType(id).String())
-					}
-					return fmt.Errorf("path: %s", buf.String())
-				}(),
-			)
+			loopErr := new(DependencyLoopError)
+			// path contains id, since it is being visited
+			for _, pathID := range path[slices.Index(path, id):] {
+				pathValue, _ := valuesTemplate.Load(pathID)
+				loopErr.Cycle = append(loopErr.Cycle, typeIDToType(pathID))
+				errValues = append(errValues, pathValue)
+			}
+			return false, loopErr
 
 		case 2: // Black: Already processed
 			return needsReset[id], nil
@@ -227,24 +230,36 @@ func newForker(
 		// Recursive Step: Check Dependencies
 		for _, depID := range value.typeInfo.Dependencies {
 			if isAlwaysProvided(depID) {
-				// InjectStruct, Fork, and Reset are opaque dependencies: a
-				// provider receiving one of them can dynamically pull any type
-				// from the scope (InjectStruct injects struct fields, Fork
-				// creates child scopes, Reset creates reset scopes). When new
-				// definitions are added we must pessimistically assume the
+				// InjectStruct, Fork, Reset, Scope, and Call are opaque
+				// dependencies: a provider receiving one of them can
+				// dynamically pull any type from the scope (InjectStruct
+				// injects struct fields, Fork creates child scopes, Reset
+				// creates reset scopes, Scope and Call resolve directly). When
+				// new definitions are added we must pessimistically assume the
 				// opaque dependency depends on them and force a reset so the
 				// provider is re-evaluated against the new scope.
-				if (depID == injectStructTypeID || depID == forkTypeID || depID == resetTypeID) && len(newValuesTemplate) > 0 {
+				if isOpaqueDependency(depID) && len(newValuesTemplate) > 0 {
 					reset = true
 				}
 				continue
 			}
 			depValue, ok := valuesTemplate.Load(depID)
 			if !ok {
-				return false, errors.Join(
-					fmt.Errorf("dependency not found in definition %v, no definition for %v", value.typeInfo.DefType, typeIDToType(depID)),
-					ErrDependencyNotFound,
-				)
+				if err := privateDependencyError(valuesTemplate, value.typeInfo.DefType, depID); err != nil {
+					return false, err
+				}
+				notFoundErr := &DependencyNotFoundError{
+					Type:       typeIDToType(depID),
+					RequiredBy: value.typeInfo.DefType,
+					Suggestions: suggest(Scope{
+						values: valuesTemplate,
+					}, typeIDToType(depID)),
+				}
+				for _, pathID := range append(path, value.typeInfo.TypeID) {
+					notFoundErr.Path = append(notFoundErr.Path, typeIDToType(pathID))
+				}
+				errValues = []_Value{value}
+				return false, notFoundErr
 			}
 			depResets, err := traverse(depValue, append(path, value.typeInfo.TypeID))
 			if err != nil {
@@ -258,29 +273,72 @@ func newForker(
 
 	// 4. Analyze All Types in Conceptual Scope:
 	//    - Populate `needsReset` and detect loops globally via `traverse`.
-	//    - Collect `defTypeIDs` for signature.
+	//    - Collect `defTypeIDs` and `typeIDs` for signature.
 	defTypeIDs := make([]_TypeID, 0, valuesTemplate.Len()) // For signature
+	typeIDs := make([]_TypeID, 0, valuesTemplate.Len())    // For signature
 
+	var traverseErr error
 	for value := range valuesTemplate.IterValues() {
 		if _, err := traverse(value, nil); err != nil {
-			panic(err)
+			traverseErr = err
+			break
 		}
 
+		typeIDs = append(typeIDs, value.typeInfo.TypeID)
+
 		// Collect definition type IDs (sorted insert)
 		defTypeID := getTypeID(value.typeInfo.DefType)
 		i, found := slices.BinarySearch(defTypeIDs, defTypeID)
 		if !found {
 			defTypeIDs = slices.Insert(defTypeIDs, i, defTypeID)
 		}
+		if module := value.typeInfo.Module; module != nil {
+			// module IDs distinguish private namespaces and contracts
+			i, found := slices.BinarySearch(defTypeIDs, module.ID)
+			if !found {
+				defTypeIDs = slices.Insert(defTypeIDs, i, module.ID)
+			}
+		}
+
+	}
+
+	if traverseErr != nil {
+		// describe where the definitions came from
+		var sources []string
+		for _, value := range errValues {
+			if i, ok := newDefOutputIDs[value.typeInfo.TypeID]; ok {
+				sources = append(sources, definitionSource(defs[i], true))
+			} else {
+				sources = append(sources, valueSource(value))
+			}
+		}
+		switch err := traverseErr.(type) {
+		case *DependencyLoopError:
+			err.Sources = sources
+		case *DependencyNotFoundError:
+			err.Source = sources[0]
+		}
+		panic(traverseErr)
+	}
 
+	if err := checkModuleContracts(valuesTemplate); err != nil {
+		panic(err)
 	}
 
-	// 5. Calculate Child Scope Signature: Hash sorted definition type IDs.
+	// 5. Calculate Child Scope Signature: Hash sorted definition type IDs and
+	//    provided type IDs. Definition types alone do not determine the
+	//    structure, since Without and Prune may keep some outputs of a
+	//    multi-output definition only.
+	slices.Sort(typeIDs)
 	h := sha256.New()
-	buf := make([]byte, 0, len(defTypeIDs)*8)
+	buf := make([]byte, 0, (len(defTypeIDs)+len(typeIDs)+1)*8)
+	buf = binary.NativeEndian.AppendUint64(buf, uint64(len(defTypeIDs)))
 	for _, id := range defTypeIDs {
 		buf = binary.NativeEndian.AppendUint64(buf, uint64(id))
 	}
+	for _, id := range typeIDs {
+		buf = binary.NativeEndian.AppendUint64(buf, uint64(id))
+	}
 	// h.Write (from sha256.New()) is not expected to return an error,
 	// but check is included for robustness.
 	if _, err := h.Write(buf); err != nil {
@@ -316,6 +374,102 @@ func newForker(
 	}
 }
 
+// definitionError returns the error for an invalid definition of type
+// defType and value defValue, or nil.
+func definitionError(defType reflect.Type, defValue reflect.Value) error {
+	switch defType.Kind() {
+	case reflect.Func:
+		if defValue.IsNil() {
+			return &BadArgumentError{
+				Reason: fmt.Sprintf("%v nil function provided", defType),
+			}
+		}
+		if defType.NumOut() == 0 {
+			return &BadArgumentError{
+				Reason: fmt.Sprintf("%v returns nothing", defType),
+			}
+		}
+	case reflect.Pointer:
+		if defValue.IsNil() {
+			return &BadArgumentError{
+				Reason: fmt.Sprintf("%v nil pointer provided", defType),
+			}
+		}
+	default:
+		return &BadArgumentError{
+			Reason: fmt.Sprintf("%v is not a valid definition", defType),
+		}
+	}
+	return nil
+}
+
+// redefinitionError returns the error for def redefining t, inherited from
+// the definition of inherited, without Override in a strict scope.
+func redefinitionError(def any, t reflect.Type, inherited _Value) error {
+	return &RedefinitionError{
+		Type:       t,
+		DefType:    definitionType(def),
+		Definition: definitionSource(def, true),
+		Inherited:  valueSource(inherited),
+	}
+}
+
+// skippedDefaults reports, for each definition in defs, whether it is a Default
+// definition that does not apply because a type it provides is defined by the
+// scope, by a non-default definition, or by an earlier Default definition.
+// It returns nil if no definition is skipped.
+func skippedDefaults(scope Scope, defs []any) (skipped []bool) {
+	hasDefaults := false
+	for _, def := range defs {
+		if definitionFlags(def)&defDefault != 0 {
+			hasDefaults = true
+			break
+		}
+	}
+	if !hasDefaults {
+		return nil
+	}
+
+	defined := make(map[_TypeID]struct{})
+	for _, def := range defs {
+		if definitionFlags(def)&defDefault != 0 {
+			continue
+		}
+		for _, t := range definitionOutputTypes(def) {
+			defined[definitionTypeID(def, t)] = struct{}{}
+		}
+	}
+	for i, def := range defs {
+		if definitionFlags(def)&defDefault == 0 {
+			continue
+		}
+		outputs := definitionOutputTypes(def)
+		skip := false
+		for _, t := range outputs {
+			id := definitionTypeID(def, t)
+			if _, ok := defined[id]; ok {
+				skip = true
+				break
+			}
+			if _, ok := scope.values.Load(id); ok {
+				skip = true
+				break
+			}
+		}
+		if skip {
+			if skipped == nil {
+				skipped = make([]bool, len(defs))
+			}
+			skipped[i] = true
+			continue
+		}
+		for _, t := range outputs {
+			defined[definitionTypeID(def, t)] = struct{}{}
+		}
+	}
+	return
+}
+
 // Fork applies the pre-calculated changes from the _Forker to the parent scope, creating a child scope.
 func (f *_Forker) Fork(s Scope, defs []any) Scope {
 
@@ -326,19 +480,8 @@ func (f *_Forker) Fork(s Scope, defs []any) Scope {
 	}
 
 	// 2. Handle Parent Scope Stack: Flatten if deep.
-	if s.values != nil && s.values.Height > 16 { // Threshold for flattening
-		var flatValues []_Value
-		for parentValue := range s.values.IterValues() {
-			flatValues = append(flatValues, parentValue)
-		}
-
-		slices.SortFunc(flatValues, func(a, b _Value) int { // Sort flattened values
-			return cmp.Compare(a.typeInfo.TypeID, b.typeInfo.TypeID)
-		})
-		scope.values = &_StackedMap{
-			Values: flatValues,
-			Height: 1,
-		}
+	if s.values != nil && s.values.Height > maxStackHeight {
+		scope.values = s.values.flatten()
 	} else {
 		scope.values = s.values // Inherit parent stack top
 	}
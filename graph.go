@@ -0,0 +1,241 @@
+package dscope
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+)
+
+const TheoryOfDependencyGraph = `
+dscope dependency graph theory:
+- Dependents, DependencyClosure and InitOrder query the static dependency
+  graph of a scope, formed by the parameters of provider functions. They never
+  evaluate providers.
+- InjectStruct, Fork, Reset, Scope and Call are opaque built-ins: a provider
+  depending on one of them may resolve any type at runtime. Queries do not
+  guess what such a provider resolves; they flag it instead. Dependents lists
+  the providers that may reach the queried type only through an opaque
+  built-in as opaque dependents, and InitOrder lists opaque built-ins as opaque
+  dependencies.
+- Types provided by resolvers are part of the graph if the resolvers of the
+  scope can provide them, as with Get.
+`
+
+// Dependent is a type whose value depends on a queried type.
+type Dependent struct {
+	Type reflect.Type
+	// Direct reports whether the definition of Type has the queried type as a
+	// parameter
+	Direct bool
+	// Opaque reports whether Type may depend on the queried type only through
+	// an opaque built-in, directly or through other dependents
+	Opaque bool
+}
+
+// Dependency is a type resolved for a set of roots.
+type Dependency struct {
+	Type reflect.Type
+	// Root reports whether Type is one of the roots
+	Root bool
+	// Opaque reports whether Type is an opaque built-in, through which types
+	// not listed may be resolved at runtime
+	Opaque bool
+}
+
+// Dependents returns the types whose values depend on t, directly or
+// transitively. Static dependents come first, direct ones before others, each
+// group sorted by type name.
+func (scope Scope) Dependents(t reflect.Type) (ret []Dependent) {
+	id := getTypeID(t)
+	dependents := make(map[_TypeID][]_TypeID)
+	var opaque []_TypeID
+	for value := range scope.values.IterValues() {
+		valueID := value.typeInfo.TypeID
+		for _, depID := range value.typeInfo.Dependencies {
+			if isOpaqueDependency(depID) && depID != id {
+				opaque = append(opaque, valueID)
+				continue
+			}
+			dependents[depID] = append(dependents[depID], valueID)
+		}
+	}
+
+	included := map[_TypeID]bool{
+		id: true,
+	}
+	direct := make(map[_TypeID]bool)
+	for _, valueID := range dependents[id] {
+		direct[valueID] = true
+	}
+	visit := func(seeds []_TypeID, isOpaque bool) {
+		queue := slices.Clone(seeds)
+		for len(queue) > 0 {
+			valueID := queue[0]
+			queue = queue[1:]
+			if included[valueID] {
+				continue
+			}
+			included[valueID] = true
+			ret = append(ret, Dependent{
+				Type:   typeIDToType(valueID),
+				Direct: direct[valueID],
+				Opaque: isOpaque,
+			})
+			queue = append(queue, dependents[valueID]...)
+		}
+	}
+	visit(dependents[id], false)
+	// the remaining values depending on opaque built-ins may resolve t
+	visit(opaque, true)
+
+	slices.SortStableFunc(ret, func(a, b Dependent) int {
+		if a.Opaque != b.Opaque {
+			if b.Opaque {
+				return -1
+			}
+			return 1
+		}
+		if a.Direct != b.Direct {
+			if a.Direct {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.Type.String(), b.Type.String())
+	})
+	return
+}
+
+// DependencyClosure returns the types calling fn would resolve, in the order
+// of InitOrder with the parameter types of fn as roots.
+func (scope Scope) DependencyClosure(fn any) []Dependency {
+	fnType := validateCallableValue(reflect.ValueOf(fn))
+	roots := make([]reflect.Type, 0, fnType.NumIn())
+	for i := range fnType.NumIn() {
+		roots = append(roots, fnType.In(i))
+	}
+	return scope.InitOrder(roots...)
+}
+
+// InitOrder returns the types resolving roots would resolve, each after its
+// dependencies. It panics with a DependencyNotFoundError if a type has no
+// definition.
+func (scope Scope) InitOrder(roots ...reflect.Type) (ret []Dependency) {
+	rootIDs := make(map[_TypeID]bool, len(roots))
+	for _, t := range roots {
+		rootIDs[getTypeID(t)] = true
+	}
+	for _, id := range scope.initOrder(roots) {
+		ret = append(ret, Dependency{
+			Type:   typeIDToType(id),
+			Root:   rootIDs[id],
+			Opaque: isOpaqueDependency(id),
+		})
+	}
+	return
+}
+
+// initOrder returns the type IDs of InitOrder, including private ones.
+func (scope Scope) initOrder(roots []reflect.Type) (ret []_TypeID) {
+	visited := make(map[_TypeID]bool)
+	var visit func(id _TypeID)
+	visit = func(id _TypeID) {
+		if visited[id] {
+			return
+		}
+		visited[id] = true
+		if !isAlwaysProvided(id) {
+			for _, depID := range scope.staticDependencies(id) {
+				visit(depID)
+			}
+		}
+		ret = append(ret, id)
+	}
+	for _, t := range roots {
+		visit(getTypeID(t))
+	}
+	return
+}
+
+// staticDependencies returns the dependencies of the definition of id,
+// panicking if there is none.
+func (scope Scope) staticDependencies(id _TypeID) []_TypeID {
+	if value, ok := scope.values.Load(id); ok {
+		return value.typeInfo.Dependencies
+	}
+	if scope.resolvers != nil {
+		if v, ok := scope.resolvers.Values.Load(id); ok {
+			return v.(_Value).typeInfo.Dependencies
+		}
+		if def, ok := scope.resolvers.resolve(typeIDToType(id)); ok {
+			var ret []_TypeID
+			for _, t := range definitionInputTypes(def) {
+				ret = append(ret, getTypeID(t))
+			}
+			return ret
+		}
+	}
+	throwErrDependencyNotFound(scope, typeIDToType(id))
+	return nil
+}
+
+// dependentClosure returns the values in values that are seeds or depend on a
+// seed, directly or transitively, in no particular order. Values depending on
+// opaque dependencies are included whenever the closure is not empty, since
+// they may resolve any type at runtime. Values sharing an initializer with an
+// included value are included as well, because the initializer computes all of
+// them at once.
+func dependentClosure(values *_StackedMap, seeds map[_TypeID]struct{}) (ret []_Value) {
+	if len(seeds) == 0 {
+		return nil
+	}
+
+	dependents := make(map[_TypeID][]_Value)
+	byInitializer := make(map[int64][]_Value)
+	var opaque []_Value
+	all := make(map[_TypeID]_Value)
+	for value := range values.IterValues() {
+		all[value.typeInfo.TypeID] = value
+		byInitializer[value.initializer.ID] = append(byInitializer[value.initializer.ID], value)
+		for _, depID := range value.typeInfo.Dependencies {
+			if isOpaqueDependency(depID) {
+				opaque = append(opaque, value)
+				continue
+			}
+			dependents[depID] = append(dependents[depID], value)
+		}
+	}
+
+	included := make(map[_TypeID]struct{})
+	var queue []_Value
+	include := func(value _Value) {
+		if _, ok := included[value.typeInfo.TypeID]; ok {
+			return
+		}
+		included[value.typeInfo.TypeID] = struct{}{}
+		ret = append(ret, value)
+		queue = append(queue, value)
+	}
+	for id := range seeds {
+		if value, ok := all[id]; ok {
+			include(value)
+		}
+	}
+	if len(ret) > 0 {
+		for _, value := range opaque {
+			include(value)
+		}
+	}
+	for len(queue) > 0 {
+		value := queue[0]
+		queue = queue[1:]
+		for _, v := range byInitializer[value.initializer.ID] {
+			include(v)
+		}
+		for _, v := range dependents[value.typeInfo.TypeID] {
+			include(v)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,146 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDependents(t *testing.T) {
+	type Clock int
+	type Timer int
+	type Scheduler int
+	type Unrelated int
+	type Dynamic int
+	type DynamicUser int
+	scope := New(
+		func() Clock {
+			return 1
+		},
+		func(c Clock) Timer {
+			return Timer(c)
+		},
+		func(t Timer) Scheduler {
+			return Scheduler(t)
+		},
+		func() Unrelated {
+			return 1
+		},
+		func(fork Fork) Dynamic {
+			return 1
+		},
+		func(d Dynamic) DynamicUser {
+			return 1
+		},
+	)
+
+	dependents := scope.Dependents(reflect.TypeFor[Clock]())
+	expected := []Dependent{
+		{Type: reflect.TypeFor[Timer](), Direct: true},
+		{Type: reflect.TypeFor[Scheduler]()},
+		{Type: reflect.TypeFor[Dynamic](), Opaque: true},
+		{Type: reflect.TypeFor[DynamicUser](), Opaque: true},
+	}
+	if !reflect.DeepEqual(dependents, expected) {
+		t.Fatalf("got %+v", dependents)
+	}
+
+	// dependents of an opaque built-in are static
+	dependents = scope.Dependents(reflect.TypeFor[Fork]())
+	expected = []Dependent{
+		{Type: reflect.TypeFor[Dynamic](), Direct: true},
+		{Type: reflect.TypeFor[DynamicUser]()},
+	}
+	if !reflect.DeepEqual(dependents, expected) {
+		t.Fatalf("got %+v", dependents)
+	}
+}
+
+func TestInitOrder(t *testing.T) {
+	type A int
+	type B int
+	type C int
+	type D int
+	scope := New(
+		func() A {
+			return 1
+		},
+		func(a A) B {
+			return 1
+		},
+		func(a A, b B, scope Scope) C {
+			return 1
+		},
+		func(c C) D {
+			return 1
+		},
+	)
+
+	order := scope.InitOrder(reflect.TypeFor[D](), reflect.TypeFor[B]())
+	expected := []Dependency{
+		{Type: reflect.TypeFor[A]()},
+		{Type: reflect.TypeFor[B](), Root: true},
+		{Type: reflect.TypeFor[Scope](), Opaque: true},
+		{Type: reflect.TypeFor[C]()},
+		{Type: reflect.TypeFor[D](), Root: true},
+	}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("got %+v", order)
+	}
+
+	closure := scope.DependencyClosure(func(b B, d D) {})
+	expected = []Dependency{
+		{Type: reflect.TypeFor[A]()},
+		{Type: reflect.TypeFor[B](), Root: true},
+		{Type: reflect.TypeFor[Scope](), Opaque: true},
+		{Type: reflect.TypeFor[C]()},
+		{Type: reflect.TypeFor[D](), Root: true},
+	}
+	if !reflect.DeepEqual(closure, expected) {
+		t.Fatalf("got %+v", closure)
+	}
+
+	// providers are not evaluated
+	scope = New(func() A {
+		panic("should not run")
+	})
+	if order := scope.InitOrder(reflect.TypeFor[A]()); len(order) != 1 {
+		t.Fatalf("got %+v", order)
+	}
+}
+
+func TestInitOrderResolvers(t *testing.T) {
+	type A int
+	type B int
+	scope := New(
+		func() A {
+			return 1
+		},
+		Resolver(func(t reflect.Type) (any, bool) {
+			if t == reflect.TypeFor[B]() {
+				return func(a A) B {
+					return B(a)
+				}, true
+			}
+			return nil, false
+		}),
+	)
+	order := scope.InitOrder(reflect.TypeFor[B]())
+	expected := []Dependency{
+		{Type: reflect.TypeFor[A]()},
+		{Type: reflect.TypeFor[B](), Root: true},
+	}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("got %+v", order)
+	}
+}
+
+func TestInitOrderNotFound(t *testing.T) {
+	type A int
+	err := recoverError(t, func() {
+		New().InitOrder(reflect.TypeFor[A]())
+	})
+	if !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("got %v", err)
+	}
+}
@@ -2,6 +2,8 @@ package dscope
 
 import (
 	"reflect"
+	"runtime/debug"
+	"slices"
 	"sync"
 	"sync/atomic"
 )
@@ -22,16 +24,25 @@ dscope lazy initialization theory:
   Subsequent accesses re-invoke the provider to reproduce the original error.
 - Reset initializers (created on Fork when dependencies change) inherit
   this contract: a fresh initializer always re-evaluates on first access.
+- A provider panic is re-panicked as a ProviderError carrying the failing
+  definition type, the original panic value and stack. While the panic unwinds
+  through the initializers of dependents, each prepends the type it was
+  resolving, so the error records the full resolution chain.
+- Re-entrant resolution of a locked initializer is reported as a dependency
+  loop instead of a deadlock (see TheoryOfReentrantResolution).
 `
 
 type _Initializer struct {
-	Def          any
-	DefIsPointer bool
-	Values       []reflect.Value
-	_values      [1]reflect.Value
-	ID           int64
-	done         atomic.Bool
+	// Def is the definition as passed to Fork, which may wrap the provider
+	Def     any
+	Values  []reflect.Value
+	_values [1]reflect.Value
+	ID      int64
+	// owner is the frame evaluating the provider while mu is held
+	owner        atomic.Pointer[_ResolvingFrame]
 	mu           sync.Mutex
+	done         atomic.Bool
+	DefIsPointer bool
 }
 
 func newInitializer(def any, isPointer bool) *_Initializer {
@@ -41,7 +52,7 @@ func newInitializer(def any, isPointer bool) *_Initializer {
 		DefIsPointer: isPointer,
 	}
 	if isPointer {
-		definitionValue := reflect.ValueOf(def).Elem()
+		definitionValue := reflect.ValueOf(definitionFunc(def)).Elem()
 		copiedValue := reflect.New(definitionValue.Type()).Elem()
 		copiedValue.Set(definitionValue)
 		ret._values[0] = copiedValue
@@ -68,12 +79,70 @@ var nextInitializerID int64 = 42
 
 func (i *_Initializer) get(scope Scope, position int) (ret reflect.Value) {
 	if !i.DefIsPointer && !i.done.Load() {
-		i.mu.Lock()
-		defer i.mu.Unlock()
-		if !i.done.Load() {
-			i.Values = scope.CallValue(reflect.ValueOf(i.Def)).Values
-			i.done.Store(true)
-		}
+		i.initialize(scope, position)
 	}
 	return i.Values[position]
 }
+
+// initialize evaluates the provider, wrapping panics in ProviderError.
+func (i *_Initializer) initialize(scope Scope, position int) {
+	t := definitionType(i.Def).Out(position)
+	i.lock(scope.resolving)
+	defer i.mu.Unlock()
+	if i.done.Load() {
+		return
+	}
+	frame := &_ResolvingFrame{
+		Initializer: i,
+		Type:        t,
+		Parent:      scope.resolving,
+	}
+	i.owner.Store(frame)
+	defer i.owner.Store(nil)
+	scope.resolving = frame
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		}
+		if _, ok := p.(*DependencyLoopError); ok {
+			// not a provider failure
+			panic(p)
+		}
+		if err, ok := p.(*ProviderError); ok {
+			// a dependency failed
+			err.Chain = slices.Insert(err.Chain, 0, t)
+			panic(err)
+		}
+		panic(&ProviderError{
+			DefType: definitionType(i.Def),
+			Chain:   []reflect.Type{t},
+			Value:   p,
+			Stack:   debug.Stack(),
+		})
+	}()
+	i.Values = scope.CallValue(reflect.ValueOf(definitionFunc(i.Def))).Values
+	i.done.Store(true)
+}
+
+// lock locks i for evaluation requested by frame, panicking with a
+// DependencyLoopError if that would never return.
+func (i *_Initializer) lock(frame *_ResolvingFrame) {
+	if i.mu.TryLock() {
+		return
+	}
+	if frame == nil {
+		// not requested by a provider, holding no initializer
+		i.mu.Lock()
+		return
+	}
+	if err := reentrantLoop(frame, i); err != nil {
+		panic(err)
+	}
+	waitingFrames.Store(frame, i)
+	defer waitingFrames.Delete(frame)
+	if err := waitLoop(frame, i); err != nil {
+		panic(err)
+	}
+	i.mu.Lock()
+}
@@ -18,7 +18,11 @@ func TestInitializerPanicRetry(t *testing.T) {
 				if p == nil {
 					t.Fatalf("call %d: should panic", i)
 				}
-				if str := fmt.Sprintf("%v", p); str != "provider panic" {
+				err, ok := p.(*ProviderError)
+				if !ok {
+					t.Fatalf("call %d: expected ProviderError, got %T", i, p)
+				}
+				if str := fmt.Sprintf("%v", err.Value); str != "provider panic" {
 					t.Fatalf("call %d: expected 'provider panic', got %v", i, str)
 				}
 			}()
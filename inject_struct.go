@@ -1,7 +1,6 @@
 package dscope
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
 	"sync"
@@ -23,16 +22,14 @@ var injectStructFuncs sync.Map
 func injectStruct(scope Scope, target any, depth int) {
 	v := reflect.ValueOf(target)
 	if !v.IsValid() {
-		panic(errors.Join(
-			fmt.Errorf("target must be a pointer to a struct, got nil"),
-			ErrBadArgument,
-		))
+		panic(&BadArgumentError{
+			Reason: "target must be a pointer to a struct, got nil",
+		})
 	}
 	if v.Kind() != reflect.Pointer {
-		panic(errors.Join(
-			fmt.Errorf("target must be a pointer to a struct, got %v", v.Type()),
-			ErrBadArgument,
-		))
+		panic(&BadArgumentError{
+			Reason: fmt.Sprintf("target must be a pointer to a struct, got %v", v.Type()),
+		})
 	}
 	targetType := v.Type()
 	if fn, ok := injectStructFuncs.Load(targetType); ok {
@@ -44,40 +41,18 @@ func injectStruct(scope Scope, target any, depth int) {
 	fn.(_InjectStructFunc)(scope, v, depth)
 }
 
-func makeInjectStructFunc(t reflect.Type) _InjectStructFunc {
-	numDeref := 0
-l:
-	for {
-		if numDeref > 100 {
-			panic(errors.Join(
-				fmt.Errorf("too many dereferences or recursive pointer type %v", t),
-				ErrBadArgument,
-			))
-		}
-		switch t.Kind() {
-		case reflect.Pointer:
-			numDeref++
-			// This may causes stack overflow for recursive pointer types.
-			// Fixing this will introduce extra cost for common cases.
-			// So we choose not to handle recursive pointer types, just let it crash.
-			t = t.Elem()
-		case reflect.Struct:
-			break l
-		default:
-			panic(errors.Join(
-				fmt.Errorf("target type %v is not a struct or pointer to struct", t),
-				ErrBadArgument,
-			))
-		}
-	}
+// _StructFieldInfo describes a struct field handled by struct injection.
+type _StructFieldInfo struct {
+	Field      reflect.StructField
+	IsInject   bool
+	IsEmbedded bool
+	Type       reflect.Type
+}
 
-	type FieldInfo struct {
-		Field      reflect.StructField
-		IsInject   bool
-		IsEmbedded bool
-		Type       reflect.Type
-	}
-	var infos []FieldInfo
+// injectStructFields returns the fields of struct type t that struct injection
+// fills: Inject[T] fields, fields tagged dscope:"." or dscope:"inject", and
+// embedded structs or pointers to structs.
+func injectStructFields(t reflect.Type) (infos []_StructFieldInfo) {
 	for i := range t.NumField() {
 		field := t.Field(i)
 
@@ -91,14 +66,14 @@ l:
 			// Only treat as Inject[T] if it is a function.
 			// Pointers to Inject[T] also implement the interface but cannot be
 			// processed by reflect.MakeFunc or Out(0).
-			infos = append(infos, FieldInfo{
+			infos = append(infos, _StructFieldInfo{
 				Field:    field,
 				IsInject: true,
 				Type:     field.Type.Out(0),
 			})
 
 		} else if directive == "." || directive == "inject" {
-			infos = append(infos, FieldInfo{
+			infos = append(infos, _StructFieldInfo{
 				Field: field,
 				Type:  field.Type,
 			})
@@ -112,7 +87,7 @@ l:
 			} else if fieldType.Kind() != reflect.Struct {
 				continue
 			}
-			infos = append(infos, FieldInfo{
+			infos = append(infos, _StructFieldInfo{
 				Field:      field,
 				IsEmbedded: true,
 				Type:       field.Type,
@@ -121,21 +96,48 @@ l:
 		}
 
 	}
+	return
+}
+
+func makeInjectStructFunc(t reflect.Type) _InjectStructFunc {
+	numDeref := 0
+l:
+	for {
+		if numDeref > 100 {
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("too many dereferences or recursive pointer type %v", t),
+			})
+		}
+		switch t.Kind() {
+		case reflect.Pointer:
+			numDeref++
+			// This may causes stack overflow for recursive pointer types.
+			// Fixing this will introduce extra cost for common cases.
+			// So we choose not to handle recursive pointer types, just let it crash.
+			t = t.Elem()
+		case reflect.Struct:
+			break l
+		default:
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("target type %v is not a struct or pointer to struct", t),
+			})
+		}
+	}
+
+	infos := injectStructFields(t)
 
 	return func(scope Scope, value reflect.Value, depth int) {
 		if depth > 64 {
-			panic(errors.Join(
-				fmt.Errorf("recursive struct injection depth limit exceeded"),
-				ErrBadArgument,
-			))
+			panic(&BadArgumentError{
+				Reason: "recursive struct injection depth limit exceeded",
+			})
 		}
 
 		// Check if the target pointer is nil before dereferencing
 		if value.Kind() == reflect.Pointer && value.IsNil() {
-			panic(errors.Join(
-				fmt.Errorf("cannot inject into a nil pointer target of type %v", value.Type()),
-				ErrBadArgument,
-			))
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("cannot inject into a nil pointer target of type %v", value.Type()),
+			})
 		}
 
 		for range numDeref {
@@ -143,10 +145,9 @@ l:
 				if value.CanSet() {
 					value.Set(reflect.New(value.Type().Elem()))
 				} else {
-					panic(errors.Join(
-						fmt.Errorf("cannot inject into a nil pointer target of type %v", value.Type()),
-						ErrBadArgument,
-					))
+					panic(&BadArgumentError{
+						Reason: fmt.Sprintf("cannot inject into a nil pointer target of type %v", value.Type()),
+					})
 				}
 			}
 			value = value.Elem()
@@ -161,7 +162,7 @@ l:
 						func(_ []reflect.Value) []reflect.Value {
 							value, ok := scope.Get(info.Type)
 							if !ok {
-								throwErrDependencyNotFound(info.Type)
+								throwErrDependencyNotFound(scope, info.Type)
 							}
 							return []reflect.Value{value}
 						},
@@ -188,7 +189,7 @@ l:
 			} else {
 				v, ok := scope.Get(info.Type)
 				if !ok {
-					throwErrDependencyNotFound(info.Type)
+					throwErrDependencyNotFound(scope, info.Type)
 				}
 				value.FieldByIndex(info.Field.Index).Set(v)
 			}
@@ -0,0 +1,109 @@
+package dscope
+
+import (
+	"iter"
+	"reflect"
+)
+
+const TheoryOfIntrospection = `
+dscope introspection theory:
+- Definitions exposes a read-only description of every effective definition
+  of a scope, for tooling that needs more than the types of AllTypes: which
+  definition provides a type, what it depends on, where in the layer stack it
+  sits, and whether it has been initialized.
+- Descriptions are snapshots. Introspection never evaluates providers, and a
+  description does not change when its value is initialized later.
+- Values computed by the same provider share an initializer. InitializerID
+  identifies it, so the outputs of one multi-return provider can be grouped.
+- Built-in types such as Scope or Fork are not definitions and are not
+  described. Values private to modules are described and marked Private.
+  Values resolved lazily by resolvers are described after the layer stack
+  and marked Resolved.
+`
+
+// Definition describes an effective definition of a type in a scope.
+type Definition struct {
+	// Type is the provided type
+	Type reflect.Type
+	// DefType is the type of the definition, a function or a pointer type
+	DefType reflect.Type
+	// Position is the index of Type in the results of a function definition
+	Position int
+	// Dependencies lists the parameter types of a function definition
+	Dependencies []reflect.Type
+	// Depth is the depth of the layer of the definition in the layer stack of
+	// the scope, the top layer being 0, or -1 for lazily resolved values
+	Depth int
+	// IsPointer reports whether the definition is a pointer rather than a
+	// function
+	IsPointer bool
+	// Initialized reports whether the value has been computed
+	Initialized bool
+	// InitializerID identifies the initializer computing the value. Values
+	// computed by the same provider share it.
+	InitializerID int64
+	// Private reports whether the value is private to Module
+	Private bool
+	// Module is the type of the module configured by directives that defines
+	// the value, or nil
+	Module reflect.Type
+	// Resolved reports whether the value is lazily resolved by a resolver
+	Resolved bool
+	// Source describes where the definition came from
+	Source string
+}
+
+// Definitions yields a description of each effective definition of the
+// scope, in no particular order.
+func (scope Scope) Definitions() iter.Seq[Definition] {
+	return func(yield func(Definition) bool) {
+		for value, depth := range scope.values.IterDepths() {
+			if isAlwaysProvided(value.typeInfo.TypeID) {
+				// ignored by Scope.get
+				continue
+			}
+			if !yield(describeValue(value, depth)) {
+				return
+			}
+		}
+		if scope.resolvers == nil {
+			return
+		}
+		for _, id := range scope.resolvers.types() {
+			v, ok := scope.resolvers.Values.Load(id)
+			if !ok {
+				continue
+			}
+			definition := describeValue(v.(_Value), -1)
+			definition.Resolved = true
+			if !yield(definition) {
+				return
+			}
+		}
+	}
+}
+
+// describeValue returns the description of value defined at depth.
+func describeValue(value _Value, depth int) Definition {
+	info := value.typeInfo
+	ret := Definition{
+		Type:          typeIDToType(info.TypeID),
+		DefType:       info.DefType,
+		Position:      info.Position,
+		Depth:         depth,
+		IsPointer:     value.initializer.DefIsPointer,
+		Initialized:   value.initializer.DefIsPointer || value.initializer.done.Load(),
+		InitializerID: value.initializer.ID,
+		Source:        valueSource(value),
+	}
+	for _, id := range info.Dependencies {
+		ret.Dependencies = append(ret.Dependencies, typeIDToType(id))
+	}
+	if module, ok := privateTypeModule(info.TypeID); ok {
+		ret.Private = true
+		ret.Module = module
+	} else if info.Module != nil {
+		ret.Module = info.Module.Type
+	}
+	return ret
+}
@@ -0,0 +1,148 @@
+package dscope
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func definitionsByType(scope Scope) map[reflect.Type]Definition {
+	ret := make(map[reflect.Type]Definition)
+	for definition := range scope.Definitions() {
+		ret[definition.Type] = definition
+	}
+	return ret
+}
+
+func TestDefinitions(t *testing.T) {
+	type A int
+	type B string
+	type C float64
+	a := A(42)
+	scope := New(
+		&a,
+		func(a A) (B, C) {
+			return "foo", 1
+		},
+	)
+
+	definitions := definitionsByType(scope)
+	if len(definitions) != 3 {
+		t.Fatalf("got %v", definitions)
+	}
+
+	defA := definitions[reflect.TypeFor[A]()]
+	if !defA.IsPointer || !defA.Initialized || defA.DefType != reflect.TypeFor[*A]() {
+		t.Fatalf("got %+v", defA)
+	}
+
+	defB := definitions[reflect.TypeFor[B]()]
+	defC := definitions[reflect.TypeFor[C]()]
+	if defB.IsPointer || defB.Initialized {
+		t.Fatalf("got %+v", defB)
+	}
+	if defB.DefType != reflect.TypeFor[func(A) (B, C)]() || defB.Position != 0 || defC.Position != 1 {
+		t.Fatalf("got %+v %+v", defB, defC)
+	}
+	if !slices.Equal(defB.Dependencies, []reflect.Type{reflect.TypeFor[A]()}) {
+		t.Fatalf("got %v", defB.Dependencies)
+	}
+	// multi-return providers share the initializer
+	if defB.InitializerID != defC.InitializerID || defA.InitializerID == defB.InitializerID {
+		t.Fatalf("got %+v %+v", defB, defC)
+	}
+	if !strings.Contains(defB.Source, "introspect_test.go") {
+		t.Fatalf("got %v", defB.Source)
+	}
+
+	// snapshots are not updated
+	Get[C](scope)
+	if defC.Initialized {
+		t.Fatal("should not be updated")
+	}
+	definitions = definitionsByType(scope)
+	if !definitions[reflect.TypeFor[B]()].Initialized || !definitions[reflect.TypeFor[C]()].Initialized {
+		t.Fatalf("got %v", definitions)
+	}
+}
+
+func TestDefinitionsDepth(t *testing.T) {
+	type A int
+	type B int
+	scope := New(func() A {
+		return 1
+	}).Fork(func() B {
+		return 2
+	})
+	definitions := definitionsByType(scope)
+	if definitions[reflect.TypeFor[A]()].Depth != 1 || definitions[reflect.TypeFor[B]()].Depth != 0 {
+		t.Fatalf("got %v", definitions)
+	}
+
+	// reset scopes do not keep values
+	Get[A](scope)
+	reset := scope.Reset()
+	definitions = definitionsByType(reset)
+	defA := definitions[reflect.TypeFor[A]()]
+	if defA.Depth != 2 || defA.Initialized {
+		t.Fatalf("got %+v", defA)
+	}
+
+	// redefinitions hide inherited definitions
+	scope = scope.Fork(func() A {
+		return 3
+	})
+	n := 0
+	for definition := range scope.Definitions() {
+		if definition.Type == reflect.TypeFor[A]() {
+			n++
+			if definition.Depth != 0 {
+				t.Fatalf("got %+v", definition)
+			}
+		}
+	}
+	if n != 1 {
+		t.Fatalf("got %v", n)
+	}
+}
+
+func TestDefinitionsPrivate(t *testing.T) {
+	scope := New(new(testPrivateModule))
+	var private []Definition
+	for definition := range scope.Definitions() {
+		if definition.Private {
+			private = append(private, definition)
+		}
+	}
+	if len(private) != 1 {
+		t.Fatalf("got %v", private)
+	}
+	if private[0].Type != reflect.TypeFor[testPrivateHelper]() ||
+		private[0].Module != reflect.TypeFor[testPrivateModule]() {
+		t.Fatalf("got %+v", private[0])
+	}
+}
+
+func TestDefinitionsResolved(t *testing.T) {
+	type A int
+	scope := New(Resolver(func(t reflect.Type) (any, bool) {
+		if t == reflect.TypeFor[A]() {
+			return func() A {
+				return 1
+			}, true
+		}
+		return nil, false
+	}))
+	if len(definitionsByType(scope)) != 0 {
+		t.Fatal("should be empty")
+	}
+	Get[A](scope)
+	definition, ok := definitionsByType(scope)[reflect.TypeFor[A]()]
+	if !ok {
+		t.Fatal("should be described")
+	}
+	if !definition.Resolved || definition.Depth != -1 || !definition.Initialized {
+		t.Fatalf("got %+v", definition)
+	}
+}
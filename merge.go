@@ -0,0 +1,243 @@
+package dscope
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+const TheoryOfScopeMerge = `
+dscope merge theory:
+- Merge combines two independently built scopes into one containing the
+  definitions of both.
+- A type defined by both scopes with the same initializer, as when both were
+  forked from a common base, is not a conflict. Other overlaps are settled by a
+  MergePolicy: fail, prefer the left scope, prefer the right scope, or ask a
+  callback per type.
+- The merged graph is checked for dependency loops formed across the two
+  scopes.
+- A value keeps its initializer, and so its cached result, when every value in
+  its dependency closure is the one it had in its own scope. Other values get
+  fresh initializers. Values with opaque dependencies keep their initializers
+  only if the merge added nothing to their scope.
+- The merged scope carries the resolvers of both scopes, left first, and is
+  strict if either scope is.
+- The merged scope carries the configured modules of both scopes, so children
+  redefining a configuration type discover their methods again, as after Fork.
+`
+
+// MergeSide identifies the scope whose definition a merge keeps.
+type MergeSide int
+
+const (
+	MergeLeft MergeSide = iota + 1
+	MergeRight
+)
+
+// MergePolicy decides which definition of t to keep when both merged scopes
+// define it.
+type MergePolicy func(t reflect.Type) MergeSide
+
+var (
+	// MergeFail panics with a DuplicateDefinitionError on any conflict.
+	MergeFail MergePolicy = func(t reflect.Type) MergeSide {
+		panic(&DuplicateDefinitionError{
+			Type: t,
+		})
+	}
+
+	// MergePreferLeft keeps the definitions of the left scope.
+	MergePreferLeft MergePolicy = func(reflect.Type) MergeSide {
+		return MergeLeft
+	}
+
+	// MergePreferRight keeps the definitions of the right scope.
+	MergePreferRight MergePolicy = func(reflect.Type) MergeSide {
+		return MergeRight
+	}
+)
+
+// Merge returns a scope containing the definitions of a and b. Types defined by
+// both are settled by policy.
+func Merge(a, b Scope, policy MergePolicy) Scope {
+	if policy == nil {
+		panic(&BadArgumentError{
+			Reason: "nil merge policy",
+		})
+	}
+
+	sides := [2]map[_TypeID]_Value{
+		make(map[_TypeID]_Value),
+		make(map[_TypeID]_Value),
+	}
+	for value := range a.values.IterValues() {
+		sides[0][value.typeInfo.TypeID] = value
+	}
+	for value := range b.values.IterValues() {
+		sides[1][value.typeInfo.TypeID] = value
+	}
+
+	// choose values
+	type chosen struct {
+		value _Value
+		side  int
+	}
+	merged := make(map[_TypeID]chosen, len(sides[0])+len(sides[1]))
+	for id, value := range sides[0] {
+		merged[id] = chosen{value, 0}
+	}
+	for id, value := range sides[1] {
+		left, ok := merged[id]
+		if !ok {
+			merged[id] = chosen{value, 1}
+			continue
+		}
+		if left.value.initializer.ID == value.initializer.ID {
+			// same definition
+			continue
+		}
+		switch side := mergeSide(policy, id, left.value, value); side {
+		case MergeLeft:
+		case MergeRight:
+			merged[id] = chosen{value, 1}
+		default:
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("invalid merge side %d for %v", side, typeIDToType(id)),
+			})
+		}
+	}
+
+	// a side is unchanged if the merge resolves every type to its own value
+	var sideChanged [2]bool
+	for i, side := range sides {
+		for id, c := range merged {
+			if own, ok := side[id]; !ok || own.initializer.ID != c.value.initializer.ID {
+				sideChanged[i] = true
+				break
+			}
+		}
+	}
+
+	// detect loops and determine changed values
+	colors := make(map[_TypeID]int)
+	changed := make(map[_TypeID]bool)
+	var traverse func(id _TypeID, path []_TypeID) bool
+	traverse = func(id _TypeID, path []_TypeID) bool {
+		c := merged[id]
+		switch colors[id] {
+		case 1:
+			loopErr := new(DependencyLoopError)
+			// path contains id, since it is being visited
+			for _, pathID := range path[slices.Index(path, id):] {
+				loopErr.Cycle = append(loopErr.Cycle, typeIDToType(pathID))
+				loopErr.Sources = append(loopErr.Sources, valueSource(merged[pathID].value))
+			}
+			panic(loopErr)
+		case 2:
+			return changed[id]
+		}
+		colors[id] = 1
+		ret := false
+		for _, depID := range c.value.typeInfo.Dependencies {
+			if isOpaqueDependency(depID) {
+				ret = ret || sideChanged[c.side]
+				continue
+			}
+			if isAlwaysProvided(depID) {
+				continue
+			}
+			dep, ok := merged[depID]
+			if !ok {
+				// lazily resolved in its own scope
+				ret = true
+				continue
+			}
+			if traverse(depID, append(path, id)) {
+				ret = true
+			}
+			if own, ok := sides[c.side][depID]; !ok || own.initializer.ID != dep.value.initializer.ID {
+				ret = true
+			}
+		}
+		colors[id] = 2
+		changed[id] = ret
+		return ret
+	}
+
+	ids := slices.Sorted(maps.Keys(merged)) // values are sorted by TypeID
+	values := make([]_Value, 0, len(ids))
+	resetInitializers := make(map[int64]*_Initializer) // Share reset initializers for multi-return
+	for _, id := range ids {
+		value := merged[id].value
+		if traverse(id, nil) {
+			initID := value.initializer.ID
+			resetInit, ok := resetInitializers[initID]
+			if !ok {
+				resetInit = value.initializer.reset()
+				resetInitializers[initID] = resetInit
+			}
+			value.initializer = resetInit
+		}
+		values = append(values, value)
+	}
+
+	// signature covers the types and their definition types
+	h := sha256.New()
+	h.Write([]byte("merge"))
+	buf := make([]byte, 0, len(values)*16)
+	for _, value := range values {
+		buf = binary.NativeEndian.AppendUint64(buf, uint64(value.typeInfo.TypeID))
+		buf = binary.NativeEndian.AppendUint64(buf, uint64(getTypeID(value.typeInfo.DefType)))
+	}
+	if _, err := h.Write(buf); err != nil {
+		panic(fmt.Errorf("unexpected error during signature hash calculation in Merge: %w", err))
+	}
+	var signature _Hash
+	h.Sum(signature[:0])
+
+	var resolvers *_Resolvers
+	if funcs := append(slices.Clone(a.resolvers.funcs()), b.resolvers.funcs()...); len(funcs) > 0 {
+		var excluded map[_TypeID]struct{}
+		if a.resolvers.excluded() != nil || b.resolvers.excluded() != nil {
+			excluded = make(map[_TypeID]struct{})
+			maps.Copy(excluded, a.resolvers.excluded())
+			maps.Copy(excluded, b.resolvers.excluded())
+		}
+		resolvers = newResolvers(funcs, excluded)
+	}
+
+	return Scope{
+		values: &_StackedMap{
+			Values: values,
+			Height: 1,
+		},
+		signature:   signature,
+		forkFuncKey: signature,
+		resolvers:   resolvers,
+		strict:      a.strict || b.strict,
+		modules:     mergeModules(a.modules, b.modules),
+	}
+}
+
+// mergeSide returns the side policy keeps for id, defined by left and right.
+// A DuplicateDefinitionError the policy panics with gets the sources of both
+// definitions if it has none.
+func mergeSide(policy MergePolicy, id _TypeID, left, right _Value) MergeSide {
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		}
+		if err, ok := p.(*DuplicateDefinitionError); ok && len(err.Definitions) == 0 {
+			err.Definitions = []string{
+				valueSource(left),
+				valueSource(right),
+			}
+		}
+		panic(p)
+	}()
+	return policy(typeIDToType(id))
+}
@@ -0,0 +1,189 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	type Config string
+	type DB string
+	type Feature string
+	var dbs, features int
+	base := New(
+		func() Config { return "base" },
+		func(c Config) DB {
+			dbs++
+			return DB(c)
+		},
+	)
+	feature := New(
+		func() Config { return "base" },
+		func(c Config) Feature {
+			features++
+			return Feature(c)
+		},
+	)
+	Get[DB](base)
+	Get[Feature](feature)
+
+	merged := Merge(base, feature, MergePreferLeft)
+	if Get[DB](merged) != "base" || Get[Feature](merged) != "base" {
+		t.Fatal()
+	}
+	// DB keeps its initializer, Feature depends on the left Config
+	if dbs != 1 {
+		t.Fatalf("got %d", dbs)
+	}
+	if features != 2 {
+		t.Fatalf("got %d", features)
+	}
+
+	merged = Merge(base, feature, MergePreferRight)
+	Get[DB](merged)
+	Get[Feature](merged)
+	if dbs != 2 || features != 2 {
+		t.Fatalf("got %d %d", dbs, features)
+	}
+
+	// forks of merged scopes work as usual
+	child := merged.Fork(func() Config { return "child" })
+	if Get[DB](child) != "child" || Get[Feature](child) != "child" {
+		t.Fatal()
+	}
+}
+
+func TestMergeCommonBase(t *testing.T) {
+	type Config string
+	type A string
+	type B string
+	var configs int
+	base := New(func() Config {
+		configs++
+		return "base"
+	})
+	Get[Config](base)
+	a := base.Fork(func(c Config) A { return A(c) })
+	b := base.Fork(func(c Config) B { return B(c) })
+	merged := Merge(a, b, MergeFail)
+	if Get[A](merged) != "base" || Get[B](merged) != "base" {
+		t.Fatal()
+	}
+	if configs != 1 {
+		t.Fatalf("common definition recomputed: %d", configs)
+	}
+}
+
+func TestMergeFail(t *testing.T) {
+	a := New(Provide(1))
+	b := New(Provide(2))
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		err, ok := p.(error)
+		if !ok || !errors.Is(err, ErrBadDefinition) {
+			t.Fatalf("got %v", p)
+		}
+		var duplicate *DuplicateDefinitionError
+		if !errors.As(err, &duplicate) {
+			t.Fatalf("got %v", err)
+		}
+		// both definitions are described
+		if duplicate.Type != reflect.TypeFor[int]() || !slices.Equal(duplicate.Definitions, []string{
+			"pointer *int",
+			"pointer *int",
+		}) {
+			t.Fatalf("got %+v", duplicate)
+		}
+	}()
+	Merge(a, b, MergeFail)
+}
+
+func TestMergeCallback(t *testing.T) {
+	a := New(Provide(1), Provide("a"))
+	b := New(Provide(2), Provide("b"))
+	var conflicts []reflect.Type
+	merged := Merge(a, b, func(t reflect.Type) MergeSide {
+		conflicts = append(conflicts, t)
+		if t == reflect.TypeFor[int]() {
+			return MergeLeft
+		}
+		return MergeRight
+	})
+	if len(conflicts) != 2 {
+		t.Fatalf("got %v", conflicts)
+	}
+	if Get[int](merged) != 1 || Get[string](merged) != "b" {
+		t.Fatal()
+	}
+}
+
+func TestMergeLoop(t *testing.T) {
+	type A int
+	type B int
+	a := New(
+		func(b B) A { return A(b) },
+		func() B { return 1 },
+	)
+	b := New(
+		func(a A) B { return B(a) },
+		func() A { return 1 },
+	)
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		if err, ok := p.(error); !ok || !errors.Is(err, ErrDependencyLoop) {
+			t.Fatalf("got %v", p)
+		}
+	}()
+	Merge(a, b, func(t reflect.Type) MergeSide {
+		if t == reflect.TypeFor[A]() {
+			return MergeLeft
+		}
+		return MergeRight
+	})
+}
+
+func TestMergeOpaqueDependency(t *testing.T) {
+	type Service int
+	var services int
+	a := New(func(s Scope) Service {
+		services++
+		return Service(services)
+	})
+	Get[Service](a)
+	Get[Service](Merge(a, New(), MergeFail))
+	if services != 1 {
+		t.Fatalf("unchanged scope should keep opaque consumer: %d", services)
+	}
+	Get[Service](Merge(a, New(Provide(1)), MergeFail))
+	if services != 2 {
+		t.Fatalf("opaque consumer should be recomputed: %d", services)
+	}
+}
+
+func TestMergeBadArgument(t *testing.T) {
+	for _, policy := range []MergePolicy{
+		nil,
+		func(reflect.Type) MergeSide { return 0 },
+	} {
+		func() {
+			defer func() {
+				p := recover()
+				if p == nil {
+					t.Fatal("should panic")
+				}
+				if err, ok := p.(error); !ok || !errors.Is(err, ErrBadArgument) {
+					t.Fatalf("got %v", p)
+				}
+			}()
+			Merge(New(Provide(1)), New(Provide(2)), policy)
+		}()
+	}
+}
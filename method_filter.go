@@ -0,0 +1,143 @@
+package dscope
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+const TheoryOfMethodFiltering = `
+dscope method filtering theory:
+- Methods collects every exported method of a module as a definition, except
+  module directives and methods excluded by filters.
+- A method with a NotDefinition parameter or result is never a definition, so
+  helpers such as String or Validate can opt out where they are declared.
+- A module implementing IncludingModule has only the listed methods
+  collected; a module implementing ExcludingModule has the listed methods
+  skipped. Filters apply to the methods of the module itself, not to its
+  module fields, which are filtered by their own directives. Listing a method
+  the module does not have is a bad argument.
+- StrictMethods additionally rejects an output type provided by methods of
+  two different modules, naming both modules. Strict scopes discover modules
+  passed to Fork with StrictMethods.
+`
+
+// NotDefinition marks a module method as not being a definition when used as
+// a parameter or result type of the method.
+type NotDefinition struct{}
+
+var notDefinitionType = reflect.TypeFor[NotDefinition]()
+
+// IncludingModule is implemented by modules that collect only the listed
+// methods as definitions.
+type IncludingModule interface {
+	isModule
+	IncludeMethods() []string
+}
+
+var includingModuleType = reflect.TypeFor[IncludingModule]()
+
+// ExcludingModule is implemented by modules that do not collect the listed
+// methods as definitions.
+type ExcludingModule interface {
+	isModule
+	ExcludeMethods() []string
+}
+
+var excludingModuleType = reflect.TypeFor[ExcludingModule]()
+
+// _MethodFilter selects the methods of a module collected as definitions.
+type _MethodFilter struct {
+	Include map[string]bool // nil if not declared
+	Exclude map[string]bool
+}
+
+// newMethodFilter returns the method filter declared by the module v.
+func newMethodFilter(v reflect.Value) (ret _MethodFilter) {
+	if !v.Type().Implements(isModuleType) {
+		return
+	}
+	names := func(list []string) map[string]bool {
+		set := make(map[string]bool, len(list))
+		for _, name := range list {
+			if _, ok := v.Type().MethodByName(name); !ok {
+				panic(&BadArgumentError{
+					Reason: fmt.Sprintf("module %v has no method %s", v.Type(), name),
+				})
+			}
+			set[name] = true
+		}
+		return set
+	}
+	if v.Type().Implements(includingModuleType) {
+		ret.Include = names(v.Interface().(IncludingModule).IncludeMethods())
+	}
+	if v.Type().Implements(excludingModuleType) {
+		ret.Exclude = names(v.Interface().(ExcludingModule).ExcludeMethods())
+	}
+	return
+}
+
+// skip reports whether the i-th method of v is not collected as a definition.
+func (f _MethodFilter) skip(v reflect.Value, i int) bool {
+	if isModuleDirective(v, i) {
+		return true
+	}
+	name := v.Type().Method(i).Name
+	if f.Include != nil && !f.Include[name] {
+		return true
+	}
+	if f.Exclude[name] {
+		return true
+	}
+	methodType := v.Method(i).Type()
+	for i := range methodType.NumIn() {
+		if methodType.In(i) == notDefinitionType {
+			return true
+		}
+	}
+	for i := range methodType.NumOut() {
+		if methodType.Out(i) == notDefinitionType {
+			return true
+		}
+	}
+	return false
+}
+
+// StrictMethods is like Methods, but panics if methods of different modules
+// provide the same type.
+func StrictMethods(objects ...any) []any {
+	defs, _ := methods(objects, true, nil, nil)
+	return methodValues(defs)
+}
+
+// _MethodProvider is a module method providing a type, for StrictMethods.
+type _MethodProvider struct {
+	Module reflect.Type
+	// Source describes the method
+	Source string
+}
+
+// checkMethodCollisions records the types provided by method name of module
+// in providers, and panics with a DuplicateDefinitionError if another module
+// provides one of them.
+func checkMethodCollisions(providers map[reflect.Type]_MethodProvider, module reflect.Type, name string, method reflect.Value) {
+	provider := _MethodProvider{
+		Module: module,
+		Source: fmt.Sprintf("method %s of module %v", name, module),
+	}
+	methodType := method.Type()
+	for i := range methodType.NumOut() {
+		t := methodType.Out(i)
+		other, ok := providers[t]
+		if ok && other.Module != module {
+			definitions := []string{other.Source, provider.Source}
+			slices.Sort(definitions)
+			panic(&DuplicateDefinitionError{
+				Type:        t,
+				Definitions: definitions,
+			})
+		}
+		providers[t] = provider
+	}
+}
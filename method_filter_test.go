@@ -0,0 +1,146 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+type testFilterMarkerModule struct {
+	Module
+}
+
+func (testFilterMarkerModule) Int() int {
+	return 42
+}
+
+func (testFilterMarkerModule) String(NotDefinition) string {
+	return "module"
+}
+
+func (testFilterMarkerModule) Validate() (error, NotDefinition) {
+	return nil, NotDefinition{}
+}
+
+func TestMethodsNotDefinition(t *testing.T) {
+	scope := New(new(testFilterMarkerModule))
+	Get[int](scope)
+	if _, ok := scope.Get(reflect.TypeFor[string]()); ok {
+		t.Fatal("should be skipped")
+	}
+	if _, ok := scope.Get(reflect.TypeFor[error]()); ok {
+		t.Fatal("should be skipped")
+	}
+}
+
+type testFilterIncludeModule struct {
+	Module
+}
+
+func (testFilterIncludeModule) IncludeMethods() []string {
+	return []string{"Int"}
+}
+
+func (testFilterIncludeModule) Int() int {
+	return 42
+}
+
+func (testFilterIncludeModule) String() string {
+	return "module"
+}
+
+type testFilterExcludeModule struct {
+	Module
+}
+
+func (testFilterExcludeModule) ExcludeMethods() []string {
+	return []string{"String"}
+}
+
+func (testFilterExcludeModule) Int8() int8 {
+	return 42
+}
+
+func (testFilterExcludeModule) String() string {
+	return "module"
+}
+
+func TestMethodsIncludeExclude(t *testing.T) {
+	defs := Methods(new(testFilterIncludeModule), new(testFilterExcludeModule))
+	if len(defs) != 2 {
+		t.Fatalf("got %d definitions", len(defs))
+	}
+	scope := New(defs...)
+	Get[int](scope)
+	Get[int8](scope)
+	if _, ok := scope.Get(reflect.TypeFor[string]()); ok {
+		t.Fatal("should be skipped")
+	}
+}
+
+type testFilterUnknownModule struct {
+	Module
+}
+
+func (testFilterUnknownModule) ExcludeMethods() []string {
+	return []string{"Foo"}
+}
+
+func TestMethodsUnknownFilter(t *testing.T) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		if err, ok := p.(error); !ok || !errors.Is(err, ErrBadArgument) {
+			t.Fatalf("got %v", p)
+		}
+	}()
+	Methods(new(testFilterUnknownModule))
+}
+
+type testFilterCollidingModule struct {
+	Module
+	Include testFilterIncludeModule
+}
+
+func (testFilterCollidingModule) Int() int {
+	return 1
+}
+
+func TestStrictMethods(t *testing.T) {
+	// non-colliding
+	StrictMethods(new(testFilterIncludeModule), new(testFilterExcludeModule))
+
+	check := func(fn func()) {
+		t.Helper()
+		defer func() {
+			p := recover()
+			if p == nil {
+				t.Fatal("should panic")
+			}
+			err, ok := p.(error)
+			if !ok || !errors.Is(err, ErrBadDefinition) {
+				t.Fatalf("got %v", p)
+			}
+			var duplicate *DuplicateDefinitionError
+			if !errors.As(err, &duplicate) {
+				t.Fatalf("got %v", err)
+			}
+			if duplicate.Type != reflect.TypeFor[int]() || !slices.Equal(duplicate.Definitions, []string{
+				"method Int of module dscope.testFilterCollidingModule",
+				"method Int of module dscope.testFilterIncludeModule",
+			}) {
+				t.Fatalf("got %+v", duplicate)
+			}
+		}()
+		fn()
+	}
+	check(func() {
+		StrictMethods(new(testFilterCollidingModule))
+	})
+	check(func() {
+		New().Strict().Fork(new(testFilterCollidingModule))
+	})
+}
@@ -1,7 +1,6 @@
 package dscope
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
 )
@@ -19,33 +18,55 @@ func validateFiniteMethodsPointerChain(typ reflect.Type) {
 	visitedTypes := make(map[reflect.Type]struct{})
 	for typ.Kind() == reflect.Pointer {
 		if _, exists := visitedTypes[typ]; exists {
-			panic(errors.Join(
-				fmt.Errorf("recursive pointer type %v", typ),
-				ErrBadArgument,
-			))
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("recursive pointer type %v", typ),
+			})
 		}
 		visitedTypes[typ] = struct{}{}
 		typ = typ.Elem()
 	}
 }
 
-func Methods(objects ...any) (ret []any) {
+// Methods returns the exported methods of objects and of their module fields
+// as method values. Module directives other than method filters apply only to
+// module objects passed to Fork directly.
+func Methods(objects ...any) []any {
+	defs, _ := methods(objects, false, nil, nil)
+	return methodValues(defs)
+}
+
+// methodValues returns the method values of module definitions.
+func methodValues(defs []any) []any {
+	for i, def := range defs {
+		defs[i] = def.(_ModuleDefinition).Def
+	}
+	return defs
+}
+
+// methods collects definitions from the methods of objects. Modules are
+// configured from scope if it is not nil, statically for validation if that is
+// not nil, and the objects with configured modules are returned in
+// configured.
+func methods(objects []any, strict bool, scope *Scope, validation *_Validation) (ret []any, configured []*_ConfiguredModule) {
 	visitedTypes := make(map[reflect.Type]bool)
+	var fields map[_TypeID]struct{}                // configuration types of the current object
+	var providers map[reflect.Type]_MethodProvider // output type -> providing method
+	if strict {
+		providers = make(map[reflect.Type]_MethodProvider)
+	}
 	var extend func(reflect.Value)
 	extend = func(v reflect.Value) {
 		if !v.IsValid() {
-			panic(errors.Join(
-				fmt.Errorf("invalid value"),
-				ErrBadArgument,
-			))
+			panic(&BadArgumentError{
+				Reason: "invalid value",
+			})
 		}
 
 		// nil interface
 		if v.Kind() == reflect.Interface && v.IsNil() {
-			panic(errors.Join(
-				fmt.Errorf("invalid value: nil interface %v", v.Type()),
-				ErrBadArgument,
-			))
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("invalid value: nil interface %v", v.Type()),
+			})
 		}
 
 		t := v.Type()
@@ -62,19 +83,48 @@ func Methods(objects ...any) (ret []any) {
 				base = base.Elem()
 			}
 			if base.Kind() == reflect.Interface {
-				panic(errors.Join(
-					fmt.Errorf("invalid value: nil pointer to interface %v", t),
-					ErrBadArgument,
-				))
+				panic(&BadArgumentError{
+					Reason: fmt.Sprintf("invalid value: nil pointer to interface %v", t),
+				})
 			}
 			// Construct concrete object for typed nil pointers like (*MyStruct)(nil)
 			v = reflect.New(t.Elem())
 		}
 
+		var configFields []_StructFieldInfo
+		if scope != nil {
+			v, configFields = configureModule(*scope, v, validation)
+		}
+		for _, info := range configFields {
+			if fields == nil {
+				fields = make(map[_TypeID]struct{})
+			}
+			fields[getTypeID(info.Type)] = struct{}{}
+		}
+
 		// method sets
-		for i := range v.NumMethod() {
-			ret = append(ret, v.Method(i).Interface())
+		module := getModuleInfo(v)
+		filter := newMethodFilter(v)
+		moduleType := t
+		for moduleType.Kind() == reflect.Pointer {
+			moduleType = moduleType.Elem()
 		}
+		addMethods := func(v reflect.Value) {
+			for i := range v.NumMethod() {
+				if filter.skip(v, i) {
+					continue
+				}
+				method := v.Method(i)
+				if configFields != nil {
+					method = configuredMethod(v, i, configFields)
+				}
+				if strict {
+					checkMethodCollisions(providers, moduleType, v.Type().Method(i).Name, method)
+				}
+				ret = append(ret, newModuleDefinition(v, i, method, moduleType, module))
+			}
+		}
+		addMethods(v)
 
 		// from fields
 		for t.Kind() == reflect.Pointer {
@@ -89,9 +139,7 @@ func Methods(objects ...any) (ret []any) {
 
 			if t.Kind() == reflect.Pointer {
 				// Collect methods from intermediate pointers (e.g. *T when we started with **T)
-				for i := range v.NumMethod() {
-					ret = append(ret, v.Method(i).Interface())
-				}
+				addMethods(v)
 			}
 		}
 		if t.Kind() == reflect.Struct {
@@ -129,7 +177,16 @@ func Methods(objects ...any) (ret []any) {
 			ptr.Elem().Set(v)
 			v = ptr
 		}
+		start := len(ret)
+		fields = nil
 		extend(v)
+		if len(fields) > 0 {
+			configured = append(configured, &_ConfiguredModule{
+				Object: object,
+				Fields: fields,
+				Defs:   ret[start:len(ret):len(ret)],
+			})
+		}
 	}
 
 	return
@@ -26,6 +26,20 @@ func (TestMethodsFoo) Foo() int {
 	return 42
 }
 
+func TestMethodsValues(t *testing.T) {
+	defs := Methods(new(TestMethodsFoo))
+	if len(defs) != 1 {
+		t.Fatalf("got %v", defs)
+	}
+	fn, ok := defs[0].(func() int)
+	if !ok {
+		t.Fatalf("got %T", defs[0])
+	}
+	if fn() != 42 {
+		t.Fatal()
+	}
+}
+
 func TestMethodFromFields(t *testing.T) {
 	type Foo struct {
 		Foo TestMethodsFoo
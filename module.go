@@ -1,6 +1,11 @@
 package dscope
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
 
 type Module struct{}
 
@@ -11,3 +16,146 @@ type isModule interface {
 var isModuleType = reflect.TypeFor[isModule]()
 
 func (Module) isDscopeModule() {}
+
+// moduleDirectives are the methods through which modules configure how their
+// definitions are added. Methods does not collect them as definitions.
+var moduleDirectives = map[string]reflect.Type{
+	"PrivateTypes":   reflect.TypeFor[func() []reflect.Type](),
+	"Requires":       reflect.TypeFor[func() []reflect.Type](),
+	"Exports":        reflect.TypeFor[func() []reflect.Type](),
+	"IncludeMethods": reflect.TypeFor[func() []string](),
+	"ExcludeMethods": reflect.TypeFor[func() []string](),
+}
+
+// isModuleDirective reports whether the i-th method of v is a module directive.
+func isModuleDirective(v reflect.Value, i int) bool {
+	if !v.Type().Implements(isModuleType) {
+		return false
+	}
+	t, ok := moduleDirectives[v.Type().Method(i).Name]
+	return ok && v.Method(i).Type() == t
+}
+
+// _ModuleInfo describes a module configured by directives. Instances are
+// interned, so modules with the same type and directives share one.
+type _ModuleInfo struct {
+	// ID identifies the module type and directives in signatures and cache keys
+	ID _TypeID
+	// Type is the module struct type
+	Type reflect.Type
+	// Private maps private types to their private type IDs
+	Private map[reflect.Type]_TypeID
+	// Requires lists the types the module requires from other definitions
+	Requires []reflect.Type
+	// Exports lists the types other definitions may depend on, if HasExports
+	Exports    []reflect.Type
+	HasExports bool
+}
+
+var moduleInfos sync.Map // string -> *_ModuleInfo
+
+// moduleDirectiveTypes calls the directive method name of v and validates the
+// returned types.
+func moduleDirectiveTypes(v reflect.Value, module reflect.Type, name string) []reflect.Type {
+	types := v.MethodByName(name).Interface().(func() []reflect.Type)()
+	for _, t := range types {
+		if t == nil {
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("nil type in %s of module %v", name, module),
+			})
+		}
+	}
+	return types
+}
+
+// getModuleInfo returns the info of the module v, or nil if v is not
+// configured by directives.
+func getModuleInfo(v reflect.Value) *_ModuleInfo {
+	if !v.Type().Implements(isModuleType) {
+		return nil
+	}
+	module := v.Type()
+	for module.Kind() == reflect.Pointer {
+		module = module.Elem()
+	}
+	info := &_ModuleInfo{
+		Type: module,
+	}
+	configured := false
+	if v.Type().Implements(privateModuleType) {
+		info.Private = modulePrivateIDs(module, moduleDirectiveTypes(v, module, "PrivateTypes"))
+		configured = configured || len(info.Private) > 0
+	}
+	if v.Type().Implements(requiringModuleType) {
+		info.Requires = moduleDirectiveTypes(v, module, "Requires")
+		configured = configured || len(info.Requires) > 0
+	}
+	if v.Type().Implements(exportingModuleType) {
+		info.Exports = moduleDirectiveTypes(v, module, "Exports")
+		info.HasExports = true
+		configured = true
+	}
+	if !configured {
+		return nil
+	}
+
+	// intern by module type and directives
+	buf := new(strings.Builder)
+	fmt.Fprintf(buf, "%d", getTypeID(module))
+	for _, id := range sortedTypeIDs(info.Private) {
+		fmt.Fprintf(buf, " p%d", id)
+	}
+	for _, t := range info.Requires {
+		fmt.Fprintf(buf, " r%d", getTypeID(t))
+	}
+	if info.HasExports {
+		buf.WriteString(" e")
+		for _, t := range info.Exports {
+			fmt.Fprintf(buf, " e%d", getTypeID(t))
+		}
+	}
+	key := buf.String()
+	if interned, ok := moduleInfos.Load(key); ok {
+		return interned.(*_ModuleInfo)
+	}
+	info.ID = _TypeID(nextTypeID.Add(1))
+	interned, _ := moduleInfos.LoadOrStore(key, info)
+	return interned.(*_ModuleInfo)
+}
+
+// _ModuleDefinition is a method of a module collected by Methods.
+type _ModuleDefinition struct {
+	// Def is the method value
+	Def any
+	// Func resolves private dependencies by their private type IDs
+	Func any
+	// Module is the module configured by directives the method belongs to
+	Module *_ModuleInfo
+	// Type is the module type
+	Type reflect.Type
+	// Method is the method of the receiver type, for provenance
+	Method reflect.Method
+}
+
+// newModuleDefinition wraps method, the i-th method of the module v of type
+// moduleType. module is nil if the module is not configured by directives.
+func newModuleDefinition(v reflect.Value, i int, method reflect.Value, moduleType reflect.Type, module *_ModuleInfo) _ModuleDefinition {
+	ret := _ModuleDefinition{
+		Def:    method.Interface(),
+		Func:   method.Interface(),
+		Module: module,
+		Type:   moduleType,
+		Method: v.Type().Method(i),
+	}
+	if v.Kind() == reflect.Pointer {
+		// methods with value receivers are promoted to pointers by wrappers
+		// without source locations
+		if m, ok := v.Type().Elem().MethodByName(ret.Method.Name); ok {
+			ret.Method = m
+		}
+	}
+	if module != nil {
+		ret.Func = privateResolvingFunc(method, module.Private)
+	}
+	return ret
+}
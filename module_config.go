@@ -0,0 +1,296 @@
+package dscope
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+const TheoryOfModuleConfiguration = `
+dscope module configuration theory:
+- Module struct fields tagged dscope:"." or dscope:"inject" are configuration
+  fields. When a module is passed to Fork, a copy of the module is made and its
+  configuration fields are filled from the parent scope before the module's
+  methods are discovered, so directives see the configured module. The
+  caller's module value is never modified.
+- Each method of a configured module is a provider declaring the
+  configuration field types as dependencies, in addition to the method's
+  parameters. The provider calls the method on a fresh copy of the module with
+  configuration fields resolved from the scope it runs in, so redefining a
+  configuration type in a child Fork re-evaluates the module's providers with
+  the new values.
+- Only zero-valued configuration fields are filled. A field the caller set is
+  kept as is and is not a dependency of the module's providers.
+- Directives such as IncludeMethods may read configuration fields. When a
+  child Fork redefines a configuration type of an inherited module, the
+  module's methods are discovered again with the child's configuration:
+  methods no longer discovered are removed from the child scope, as by
+  Without, and newly discovered ones are added, as by Fork.
+- Methods called directly has no scope and does not configure modules.
+`
+
+// _ConfiguredModule is a module object passed to Fork with configuration
+// fields filled from the scope.
+type _ConfiguredModule struct {
+	// Object is the module object as passed to Fork
+	Object any
+	// Flags are the definition flags the module was annotated with
+	Flags _DefFlags
+	// Fields are the configuration types of the object and its module fields
+	Fields map[_TypeID]struct{}
+	// Defs are the definitions last discovered from the object
+	Defs []any
+}
+
+// _ConfiguredModules is an immutable list of configured modules. Scopes refer
+// to it by pointer, so Scope stays comparable.
+type _ConfiguredModules struct {
+	Modules []*_ConfiguredModule
+}
+
+// list returns the modules of m, which may be nil.
+func (m *_ConfiguredModules) list() []*_ConfiguredModule {
+	if m == nil {
+		return nil
+	}
+	return m.Modules
+}
+
+// append returns a list of the modules of m followed by modules, or m if
+// modules is empty.
+func (m *_ConfiguredModules) append(modules []*_ConfiguredModule) *_ConfiguredModules {
+	if len(modules) == 0 {
+		return m
+	}
+	return &_ConfiguredModules{
+		Modules: slices.Concat(m.list(), modules),
+	}
+}
+
+// mergeModules returns the modules of a followed by the modules of b not in a.
+func mergeModules(a, b *_ConfiguredModules) *_ConfiguredModules {
+	var added []*_ConfiguredModule
+	for _, module := range b.list() {
+		if !slices.Contains(a.list(), module) {
+			added = append(added, module)
+		}
+	}
+	if a == nil && len(added) == len(b.list()) {
+		return b
+	}
+	return a.append(added)
+}
+
+// moduleConfigFields returns the configuration fields of the module struct
+// type t.
+func moduleConfigFields(t reflect.Type) (ret []_StructFieldInfo) {
+	for _, info := range injectStructFields(t) {
+		if info.IsInject || info.IsEmbedded {
+			continue
+		}
+		ret = append(ret, info)
+	}
+	return
+}
+
+// configureModule returns a copy of the module v with configuration fields
+// filled from scope. It returns v and no fields if v has no configuration
+// fields. If validation is not nil, the fields are recorded as its
+// requirements and v is returned unfilled.
+func configureModule(scope Scope, v reflect.Value, validation *_Validation) (reflect.Value, []_StructFieldInfo) {
+	if !v.Type().Implements(isModuleType) {
+		return v, nil
+	}
+	structType := v.Type()
+	structValue := v
+	for structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+		if structValue.IsNil() {
+			structValue = reflect.New(structType).Elem()
+		} else {
+			structValue = structValue.Elem()
+		}
+	}
+	if structType.Kind() != reflect.Struct {
+		return v, nil
+	}
+	fields := moduleConfigFields(structType)
+	if len(fields) == 0 {
+		return v, nil
+	}
+
+	// fields set by the caller are kept
+	fields = slices.DeleteFunc(fields, func(info _StructFieldInfo) bool {
+		return !structValue.FieldByIndex(info.Field.Index).IsZero()
+	})
+	if len(fields) == 0 {
+		return v, nil
+	}
+	if validation != nil {
+		// fields are checked as dependencies, directives see zero values
+		for _, info := range fields {
+			validation.require(structType, fmt.Sprintf("field %s of module %v", info.Field.Name, structType), info.Type)
+		}
+		return v, fields
+	}
+
+	configured := reflect.New(structType).Elem()
+	configured.Set(structValue)
+	for _, info := range fields {
+		value, ok := scope.Get(info.Type)
+		if !ok {
+			panic(&DependencyNotFoundError{
+				Type:       info.Type,
+				RequiredBy: structType,
+				Source:     fmt.Sprintf("field %s of module %v", info.Field.Name, structType),
+			})
+		}
+		configured.FieldByIndex(info.Field.Index).Set(value)
+	}
+	return rebuildPointers(v.Type(), configured), fields
+}
+
+// rebuildPointers returns a value of type t, which is s or a pointer chain to
+// s.
+func rebuildPointers(t reflect.Type, s reflect.Value) reflect.Value {
+	if t.Kind() != reflect.Pointer {
+		return s
+	}
+	ptr := reflect.New(t.Elem())
+	ptr.Elem().Set(rebuildPointers(t.Elem(), s))
+	return ptr
+}
+
+// configuredMethod returns a function calling the i-th method of the
+// configured module v on a copy of the module with configuration fields set
+// from the leading parameters.
+func configuredMethod(v reflect.Value, i int, fields []_StructFieldInfo) reflect.Value {
+	structValue := v
+	for structValue.Kind() == reflect.Pointer {
+		structValue = structValue.Elem()
+	}
+	methodType := v.Method(i).Type()
+	ins := make([]reflect.Type, 0, len(fields)+methodType.NumIn())
+	for _, info := range fields {
+		ins = append(ins, info.Type)
+	}
+	for j := range methodType.NumIn() {
+		ins = append(ins, methodType.In(j))
+	}
+	outs := make([]reflect.Type, 0, methodType.NumOut())
+	for j := range methodType.NumOut() {
+		outs = append(outs, methodType.Out(j))
+	}
+	fnType := reflect.FuncOf(ins, outs, methodType.IsVariadic())
+	return reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+		configured := reflect.New(structValue.Type()).Elem()
+		configured.Set(structValue)
+		for j, info := range fields {
+			configured.FieldByIndex(info.Field.Index).Set(args[j])
+		}
+		method := rebuildPointers(v.Type(), configured).Method(i)
+		if methodType.IsVariadic() {
+			return method.CallSlice(args[len(fields):])
+		}
+		return method.Call(args[len(fields):])
+	})
+}
+
+// rediscoverModules discovers again the methods of the first inherited
+// configured modules of scope whose configuration types defs redefine, and
+// updates scope if a method set changed.
+func (scope Scope) rediscoverModules(inherited int, defs []any) Scope {
+	redefined := make(map[_TypeID]struct{})
+	for _, def := range defs {
+		for _, t := range definitionOutputTypes(def) {
+			redefined[definitionTypeID(def, t)] = struct{}{}
+		}
+	}
+
+	var modules []*_ConfiguredModule
+	removed := make(map[_TypeID]struct{})
+	var added []any
+	for i, module := range scope.modules.list()[:inherited] {
+		affected := false
+		for id := range module.Fields {
+			if _, ok := redefined[id]; ok {
+				affected = true
+				break
+			}
+		}
+		if !affected {
+			continue
+		}
+
+		discovered, _ := methods([]any{module.Object}, scope.strict, &scope, nil)
+		discovered = annotateDefinitions(discovered, module.Flags)
+		oldOutputs := definitionOutputIDs(module.Defs)
+		newOutputs := definitionOutputIDs(discovered)
+		if maps.Equal(oldOutputs, newOutputs) {
+			// providers depend on the configuration types and are re-evaluated
+			continue
+		}
+		for id := range oldOutputs {
+			if _, ok := newOutputs[id]; ok {
+				continue
+			}
+			if _, ok := redefined[id]; ok {
+				// defined by defs
+				continue
+			}
+			removed[id] = struct{}{}
+		}
+	discovered:
+		for _, def := range discovered {
+			isNew := false
+			for _, t := range definitionOutputTypes(def) {
+				id := definitionTypeID(def, t)
+				if _, ok := redefined[id]; ok {
+					// defs take precedence
+					continue discovered
+				}
+				if _, ok := oldOutputs[id]; !ok {
+					isNew = true
+				}
+			}
+			if isNew {
+				added = append(added, def)
+			}
+		}
+		if modules == nil {
+			modules = slices.Clone(scope.modules.list())
+		}
+		// modules are shared by scopes and never modified
+		updated := *module
+		updated.Defs = discovered
+		modules[i] = &updated
+	}
+	if modules == nil {
+		return scope
+	}
+
+	if len(removed) > 0 {
+		scope = scope.withoutIDs(removed, false)
+	}
+	if len(added) > 0 {
+		// the added definitions are discovered already
+		scope.modules = nil
+		scope = scope.Fork(added...)
+	}
+	scope.modules = &_ConfiguredModules{
+		Modules: modules,
+	}
+	return scope
+}
+
+// definitionOutputIDs returns the IDs of the types provided by defs.
+func definitionOutputIDs(defs []any) map[_TypeID]struct{} {
+	ret := make(map[_TypeID]struct{})
+	for _, def := range defs {
+		for _, t := range definitionOutputTypes(def) {
+			ret[definitionTypeID(def, t)] = struct{}{}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,195 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testConfigEnv string
+
+type testConfigGreeting string
+
+type testConfigModule struct {
+	Module
+	Env     testConfigEnv `dscope:"."`
+	Unused  int
+	Greeter testConfigGreeterModule
+}
+
+func (m testConfigModule) Greeting() testConfigGreeting {
+	return testConfigGreeting("hello " + m.Env)
+}
+
+type testConfigGreeterModule struct {
+	Module
+	Env testConfigEnv `dscope:"inject"`
+}
+
+func (m *testConfigGreeterModule) Length(g testConfigGreeting) int {
+	return len(g) + len(m.Env)
+}
+
+func TestModuleConfiguration(t *testing.T) {
+	var calls int
+	parent := New(func() testConfigEnv {
+		calls++
+		return "prod"
+	})
+	module := new(testConfigModule)
+	scope := parent.Fork(module)
+	if g := Get[testConfigGreeting](scope); g != "hello prod" {
+		t.Fatalf("got %v", g)
+	}
+	if i := Get[int](scope); i != len("hello prod")+len("prod") {
+		t.Fatalf("got %v", i)
+	}
+	if module.Env != "" {
+		t.Fatal("module should not be modified")
+	}
+
+	// redefining the configuration re-evaluates providers
+	child := scope.Fork(func() testConfigEnv {
+		return "dev"
+	})
+	if g := Get[testConfigGreeting](child); g != "hello dev" {
+		t.Fatalf("got %v", g)
+	}
+	if i := Get[int](child); i != len("hello dev")+len("dev") {
+		t.Fatalf("got %v", i)
+	}
+	if g := Get[testConfigGreeting](scope); g != "hello prod" {
+		t.Fatalf("got %v", g)
+	}
+	if calls != 1 {
+		t.Fatalf("got %d", calls)
+	}
+}
+
+type testConfigDirectiveModule struct {
+	Module
+	Features []string `dscope:"."`
+}
+
+func (m testConfigDirectiveModule) IncludeMethods() []string {
+	return m.Features
+}
+
+func (testConfigDirectiveModule) Int8() int8 {
+	return 8
+}
+
+func (testConfigDirectiveModule) Int16() int16 {
+	return 16
+}
+
+func TestModuleConfigurationDirectives(t *testing.T) {
+	scope := New(Provide([]string{"Int8"})).Fork(testConfigDirectiveModule{})
+	Get[int8](scope)
+	if _, ok := scope.Get(reflect.TypeFor[int16]()); ok {
+		t.Fatal("should be excluded")
+	}
+
+	// redefining the configuration in a child Fork discovers methods again
+	child := scope.Fork(Provide([]string{"Int16"}))
+	if i := Get[int16](child); i != 16 {
+		t.Fatalf("got %v", i)
+	}
+	if _, ok := child.Get(reflect.TypeFor[int8]()); ok {
+		t.Fatal("should be excluded")
+	}
+	grandchild := child.Fork(Provide([]string{"Int8", "Int16"}))
+	if Get[int8](grandchild) != 8 || Get[int16](grandchild) != 16 {
+		t.Fatal("should be included")
+	}
+	// the parent is unaffected
+	if _, ok := scope.Get(reflect.TypeFor[int16]()); ok {
+		t.Fatal("should be excluded")
+	}
+
+	// unrelated forks keep the method set
+	other := child.Fork(Provide(1))
+	if _, ok := other.Get(reflect.TypeFor[int8]()); ok {
+		t.Fatal("should be excluded")
+	}
+	Get[int16](other)
+}
+
+func TestModuleConfigurationMergePrune(t *testing.T) {
+	scope := New(Provide([]string{"Int8"})).Fork(testConfigDirectiveModule{})
+
+	// merged and pruned scopes discover methods again like their parents
+	merged := Merge(scope, New(Provide(1)), MergeFail)
+	pruned := scope.Prune(reflect.TypeFor[[]string](), reflect.TypeFor[int8]())
+	for _, s := range []Scope{merged, pruned} {
+		child := s.Fork(Provide([]string{"Int16"}))
+		if i := Get[int16](child); i != 16 {
+			t.Fatalf("got %v", i)
+		}
+		if _, ok := child.Get(reflect.TypeFor[int8]()); ok {
+			t.Fatal("should be excluded")
+		}
+	}
+}
+
+func TestModuleConfigurationComparable(t *testing.T) {
+	scope := New(Provide([]string{"Int8"})).Fork(testConfigDirectiveModule{})
+	other := scope.Fork(Provide([]string{"Int16"}))
+	if scope == other {
+		t.Fatal("should differ")
+	}
+	scopes := map[Scope]int{
+		scope: 1,
+		other: 2,
+	}
+	if scopes[scope] != 1 || scopes[other] != 2 {
+		t.Fatal()
+	}
+}
+
+type testConfigPresetModule struct {
+	Module
+	Env testConfigEnv `dscope:"."`
+}
+
+func (m testConfigPresetModule) Greeting() testConfigGreeting {
+	return testConfigGreeting("hello " + m.Env)
+}
+
+func TestModuleConfigurationPreset(t *testing.T) {
+	// fields set by the caller are kept and need no definition
+	scope := New(testConfigPresetModule{
+		Env: "preset",
+	})
+	if g := Get[testConfigGreeting](scope); g != "hello preset" {
+		t.Fatalf("got %v", g)
+	}
+	child := scope.Fork(func() testConfigEnv {
+		return "dev"
+	})
+	if g := Get[testConfigGreeting](child); g != "hello preset" {
+		t.Fatalf("got %v", g)
+	}
+}
+
+func TestModuleConfigurationNotFound(t *testing.T) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		err, ok := p.(error)
+		if !ok || !errors.Is(err, ErrDependencyNotFound) {
+			t.Fatalf("got %v", p)
+		}
+		var notFound *DependencyNotFoundError
+		if !errors.As(err, &notFound) {
+			t.Fatalf("got %v", err)
+		}
+		if notFound.Type != reflect.TypeFor[testConfigEnv]() ||
+			notFound.RequiredBy != reflect.TypeFor[testConfigModule]() {
+			t.Fatalf("got %+v", notFound)
+		}
+	}()
+	New(new(testConfigModule))
+}
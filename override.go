@@ -0,0 +1,36 @@
+package dscope
+
+const TheoryOfOverrides = `
+dscope override theory:
+- By default a Fork silently shadows inherited definitions of the types it
+  redefines.
+- A strict scope, created by Scope.Strict and inherited by its children,
+  rejects a Fork redefining an inherited type with ErrBadDefinition unless the
+  definition is wrapped with Override. Overrides are explicit at the call site.
+- A definition wrapped with Default applies only when none of the types it
+  provides is defined, neither by the scope nor by another definition of the
+  same Fork; otherwise it is dropped. Among several Default definitions of a
+  type, the first one applies. Defaults never count as overrides.
+- Override, Default and strictness are part of the Fork cache key, so cached
+  forkers never mix marked and unmarked definitions.
+`
+
+// Override marks def, a definition, module or conditional definition, as an
+// intended redefinition of inherited types, as required by strict scopes.
+func Override(def any) any {
+	return annotate(def, defOverride)
+}
+
+// Default marks def, a definition, module or conditional definition, as
+// applying only if the types it provides have no definitions.
+func Default(def any) any {
+	return annotate(def, defDefault)
+}
+
+// Strict returns a copy of the scope in which, like in its child scopes, Fork
+// panics when a definition not marked with Override redefines an inherited
+// type.
+func (scope Scope) Strict() Scope {
+	scope.strict = true
+	return scope
+}
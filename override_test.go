@@ -0,0 +1,143 @@
+package dscope
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStrictOverride(t *testing.T) {
+	type Logger string
+	scope := New(func() Logger {
+		return "core"
+	}).Strict()
+
+	func() {
+		defer func() {
+			p := recover()
+			if p == nil {
+				t.Fatal("should panic")
+			}
+			err, ok := p.(error)
+			if !ok || !errors.Is(err, ErrBadDefinition) {
+				t.Fatalf("got %v", p)
+			}
+			if !strings.Contains(err.Error(), "without Override") {
+				t.Fatalf("got %v", err)
+			}
+		}()
+		scope.Fork(func() Logger {
+			return "module"
+		})
+	}()
+
+	// strictness is inherited
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("should panic")
+			}
+		}()
+		scope.Fork(Provide(1)).Fork(func() Logger {
+			return "module"
+		})
+	}()
+
+	child := scope.Fork(Override(func() Logger {
+		return "module"
+	}))
+	if l := Get[Logger](child); l != "module" {
+		t.Fatalf("got %s", l)
+	}
+
+	// non-strict scopes allow silent overrides
+	lax := New(func() Logger {
+		return "core"
+	}).Fork(func() Logger {
+		return "module"
+	})
+	if l := Get[Logger](lax); l != "module" {
+		t.Fatalf("got %s", l)
+	}
+}
+
+func TestStrictReset(t *testing.T) {
+	scope := New(Provide(1)).Strict().Reset()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("should panic")
+		}
+	}()
+	scope.Fork(Provide(2))
+}
+
+func TestDefault(t *testing.T) {
+	type Clock string
+	defs := []any{
+		Default(func() Clock {
+			return "default"
+		}),
+	}
+
+	if c := Get[Clock](New(defs...)); c != "default" {
+		t.Fatalf("got %s", c)
+	}
+
+	// inherited definitions win
+	parent := New(func() Clock {
+		return "parent"
+	})
+	if c := Get[Clock](parent.Fork(defs...)); c != "parent" {
+		t.Fatalf("got %s", c)
+	}
+
+	// definitions in the same fork win, regardless of order
+	scope := New(append(defs, func() Clock {
+		return "explicit"
+	})...)
+	if c := Get[Clock](scope); c != "explicit" {
+		t.Fatalf("got %s", c)
+	}
+
+	// the first default wins
+	scope = New(append(defs, Default(func() Clock {
+		return "second"
+	}))...)
+	if c := Get[Clock](scope); c != "default" {
+		t.Fatalf("got %s", c)
+	}
+
+	// defaults do not override in strict scopes
+	strict := parent.Strict().Fork(defs...)
+	if c := Get[Clock](strict); c != "parent" {
+		t.Fatalf("got %s", c)
+	}
+}
+
+type testOverrideModule struct {
+	Module
+}
+
+func (testOverrideModule) Int() int {
+	return 2
+}
+
+func TestOverrideModule(t *testing.T) {
+	scope := New(Provide(1)).Strict().Fork(Override(new(testOverrideModule)))
+	if i := Get[int](scope); i != 2 {
+		t.Fatalf("got %d", i)
+	}
+}
+
+func TestOverrideCacheKey(t *testing.T) {
+	fn := func() int { return 1 }
+	keys := map[_Hash]bool{
+		New(fn).forkFuncKey:                    true,
+		New(Override(fn)).forkFuncKey:          true,
+		New(Default(fn)).forkFuncKey:           true,
+		Universe.Strict().Fork(fn).forkFuncKey: true,
+	}
+	if len(keys) != 4 {
+		t.Fatal("cache key should cover markers and strictness")
+	}
+}
@@ -0,0 +1,164 @@
+package dscope
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+const TheoryOfModulePrivacy = `
+dscope module privacy theory:
+- A module implementing PrivateModule keeps the definitions of its private
+  types in a namespace of its own. Private types satisfy dependencies of the
+  module's own providers only; they are not resolvable through Get, Call,
+  InjectStruct or providers outside the module.
+- Each (module type, private type) pair is identified by a dedicated type ID,
+  so private helper types of different modules never collide with each other
+  or with public definitions of the same type.
+- Providers of the module depending on a private type resolve it by its
+  private type ID. A private type must be defined by the module itself.
+- Fork reports a definition outside the module depending on a private type as
+  a bad definition naming the owning module.
+- Embedded modules are separate modules with their own private types.
+`
+
+// PrivateModule is implemented by modules that keep the definitions of some
+// types private to the module.
+type PrivateModule interface {
+	isModule
+	PrivateTypes() []reflect.Type
+}
+
+var privateModuleType = reflect.TypeFor[PrivateModule]()
+
+type _PrivateTypeKey struct {
+	Module reflect.Type
+	Type   reflect.Type
+}
+
+var (
+	privateTypeIDs sync.Map // _PrivateTypeKey -> _TypeID
+	privateTypes   sync.Map // _TypeID -> _PrivateTypeKey
+)
+
+// getPrivateTypeID returns the type ID of t private to module.
+func getPrivateTypeID(module reflect.Type, t reflect.Type) _TypeID {
+	key := _PrivateTypeKey{
+		Module: module,
+		Type:   t,
+	}
+	if v, ok := privateTypeIDs.Load(key); ok {
+		return v.(_TypeID)
+	}
+	id := _TypeID(nextTypeID.Add(1))
+	// store the reverse mappings first, see getTypeIDSlow
+	idToType.Store(id, t)
+	privateTypes.Store(id, key)
+	v, loaded := privateTypeIDs.LoadOrStore(key, id)
+	if loaded {
+		idToType.Delete(id)
+		privateTypes.Delete(id)
+		return v.(_TypeID)
+	}
+	return id
+}
+
+// privateTypeModule returns the module that id is private to.
+func privateTypeModule(id _TypeID) (reflect.Type, bool) {
+	v, ok := privateTypes.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return v.(_PrivateTypeKey).Module, true
+}
+
+// modulePrivateIDs returns the private type IDs of types in module.
+func modulePrivateIDs(module reflect.Type, types []reflect.Type) map[reflect.Type]_TypeID {
+	if len(types) == 0 {
+		return nil
+	}
+	ids := make(map[reflect.Type]_TypeID, len(types))
+	for _, t := range types {
+		if isAlwaysProvided(getTypeID(t)) {
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("built-in type %v cannot be private to module %v", t, module),
+			})
+		}
+		ids[t] = getPrivateTypeID(module, t)
+	}
+	return ids
+}
+
+// sortedTypeIDs returns the values of ids, sorted.
+func sortedTypeIDs(ids map[reflect.Type]_TypeID) []_TypeID {
+	ret := slices.Collect(maps.Values(ids))
+	slices.Sort(ret)
+	return ret
+}
+
+// privateResolvingFunc returns a function calling method with private
+// dependencies resolved by their private type IDs, or method itself if it has
+// no private dependencies.
+func privateResolvingFunc(method reflect.Value, ids map[reflect.Type]_TypeID) any {
+	methodType := method.Type()
+	inIDs := make([]_TypeID, 0, methodType.NumIn())
+	hasPrivateInputs := false
+	for i := range methodType.NumIn() {
+		t := methodType.In(i)
+		id, ok := ids[t]
+		if ok {
+			hasPrivateInputs = true
+		} else {
+			id = getTypeID(t)
+		}
+		inIDs = append(inIDs, id)
+	}
+	if !hasPrivateInputs {
+		return method.Interface()
+	}
+
+	outs := make([]reflect.Type, 0, methodType.NumOut())
+	for i := range methodType.NumOut() {
+		outs = append(outs, methodType.Out(i))
+	}
+	fnType := reflect.FuncOf([]reflect.Type{reflect.TypeFor[Scope]()}, outs, false)
+	return reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+		scope := args[0].Interface().(Scope)
+		in := make([]reflect.Value, len(inIDs))
+		for i, id := range inIDs {
+			var ok bool
+			in[i], ok = scope.get(id)
+			if !ok {
+				throwErrDependencyNotFound(scope, typeIDToType(id))
+			}
+		}
+		if methodType.IsVariadic() {
+			return method.CallSlice(in)
+		}
+		return method.Call(in)
+	}).Interface()
+}
+
+// privateDependencyError returns the error for defType depending on id,
+// which has no definition, if a definition in values provides the type of id
+// privately.
+func privateDependencyError(values *_StackedMap, defType reflect.Type, id _TypeID) error {
+	t := typeIDToType(id)
+	for value := range values.IterValues() {
+		if typeIDToType(value.typeInfo.TypeID) != t {
+			continue
+		}
+		module, ok := privateTypeModule(value.typeInfo.TypeID)
+		if !ok {
+			continue
+		}
+		return errors.Join(
+			fmt.Errorf("definition %v depends on %v, which is private to module %v", defType, t, module),
+			ErrBadDefinition,
+		)
+	}
+	return nil
+}
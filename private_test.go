@@ -0,0 +1,158 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testPrivateHelper string
+
+type testPrivateService string
+
+type testPrivateModule struct {
+	Module
+}
+
+func (testPrivateModule) PrivateTypes() []reflect.Type {
+	return []reflect.Type{
+		reflect.TypeFor[testPrivateHelper](),
+	}
+}
+
+func (testPrivateModule) Helper() testPrivateHelper {
+	return "helper"
+}
+
+func (testPrivateModule) Service(h testPrivateHelper) testPrivateService {
+	return testPrivateService("service with " + h)
+}
+
+type testPrivateOtherModule struct {
+	Module
+}
+
+func (testPrivateOtherModule) PrivateTypes() []reflect.Type {
+	return []reflect.Type{
+		reflect.TypeFor[testPrivateHelper](),
+	}
+}
+
+func (testPrivateOtherModule) Helper() testPrivateHelper {
+	return "other helper"
+}
+
+func (testPrivateOtherModule) Int(h testPrivateHelper) int {
+	return len(h)
+}
+
+func TestPrivateTypes(t *testing.T) {
+	scope := New(new(testPrivateModule))
+	if s := Get[testPrivateService](scope); s != "service with helper" {
+		t.Fatalf("got %v", s)
+	}
+
+	// not resolvable from outside
+	if _, ok := scope.Get(reflect.TypeFor[testPrivateHelper]()); ok {
+		t.Fatal("private type should not be resolvable")
+	}
+	func() {
+		defer func() {
+			p := recover()
+			if p == nil {
+				t.Fatal("should panic")
+			}
+			if err, ok := p.(error); !ok || !errors.Is(err, ErrDependencyNotFound) {
+				t.Fatalf("got %v", p)
+			}
+		}()
+		scope.Call(func(testPrivateHelper) {})
+	}()
+	for typ := range scope.AllTypes() {
+		if typ == reflect.TypeFor[testPrivateHelper]() {
+			t.Fatal("private type should not be listed")
+		}
+	}
+
+	// directives are not definitions
+	if _, ok := scope.Get(reflect.TypeFor[[]reflect.Type]()); ok {
+		t.Fatal("directive should not be a definition")
+	}
+}
+
+func TestPrivateTypesNoCollision(t *testing.T) {
+	scope := New(
+		new(testPrivateModule),
+		new(testPrivateOtherModule),
+		func() testPrivateHelper {
+			return "public"
+		},
+	)
+	if s := Get[testPrivateService](scope); s != "service with helper" {
+		t.Fatalf("got %v", s)
+	}
+	if i := Get[int](scope); i != len("other helper") {
+		t.Fatalf("got %v", i)
+	}
+	if h := Get[testPrivateHelper](scope); h != "public" {
+		t.Fatalf("got %v", h)
+	}
+
+	// overriding the public type does not affect private ones
+	scope = scope.Fork(func() testPrivateHelper {
+		return "public2"
+	})
+	if s := Get[testPrivateService](scope); s != "service with helper" {
+		t.Fatalf("got %v", s)
+	}
+}
+
+func TestPrivateTypesViolation(t *testing.T) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		err, ok := p.(error)
+		if !ok || !errors.Is(err, ErrBadDefinition) {
+			t.Fatalf("got %v", p)
+		}
+		if !strings.Contains(err.Error(), "private to module dscope.testPrivateModule") {
+			t.Fatalf("got %v", err)
+		}
+	}()
+	New(new(testPrivateModule)).Fork(func(h testPrivateHelper) string {
+		return string(h)
+	})
+}
+
+type testPrivatePublicModule struct {
+	Module
+}
+
+func (testPrivatePublicModule) Helper() testPrivateHelper {
+	return "helper"
+}
+
+func (testPrivatePublicModule) Service(h testPrivateHelper) testPrivateService {
+	return testPrivateService("service with " + h)
+}
+
+func TestPrivateTypesForkCache(t *testing.T) {
+	// same definition types, different namespaces
+	private := New(new(testPrivateModule))
+	public := New(new(testPrivatePublicModule))
+	if private.signature == public.signature {
+		t.Fatal("signatures should differ")
+	}
+	override := func() testPrivateHelper {
+		return "override"
+	}
+	if s := Get[testPrivateService](private.Fork(override)); s != "service with helper" {
+		t.Fatalf("got %v", s)
+	}
+	if s := Get[testPrivateService](public.Fork(override)); s != "service with override" {
+		t.Fatalf("got %v", s)
+	}
+}
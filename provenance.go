@@ -0,0 +1,111 @@
+package dscope
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+const TheoryOfDefinitionProvenance = `
+dscope definition provenance theory:
+- Errors about conflicting or broken definitions name where each involved
+  definition came from, so the culprit among many modules is found without
+  bisecting.
+- A module method is attributed to its module type and method, with the
+  method's file and line. A function is attributed to its name, file and
+  line as reported by runtime.FuncForPC. A pointer definition is attributed
+  to the code that passed it to Fork, which is only known while that Fork runs.
+- Provenance is computed only when an error is reported. Initializers keep
+  their definitions so that inherited definitions can be attributed too.
+`
+
+// dscopeDir is the directory of the dscope package source files.
+var dscopeDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return file[:strings.LastIndex(file, "/")+1]
+}()
+
+// isDscopeSource reports whether file is a non-test source file of the dscope
+// package.
+func isDscopeSource(file string) bool {
+	rest, ok := strings.CutPrefix(file, dscopeDir)
+	return ok && !strings.Contains(rest, "/") && !strings.HasSuffix(rest, "_test.go")
+}
+
+// callerLocation returns the file and line of the innermost caller outside
+// the dscope package. Tests of the package count as callers.
+func callerLocation() string {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.File != "" && !isDscopeSource(frame.File) && !strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// funcLocation returns the name, file and line of the function fn.
+func funcLocation(fn reflect.Value) string {
+	if !fn.IsValid() || fn.Kind() != reflect.Func || fn.IsNil() {
+		return ""
+	}
+	f := runtime.FuncForPC(fn.Pointer())
+	if f == nil || strings.HasPrefix(f.Name(), "reflect.") {
+		// functions made by reflect have no useful location
+		return ""
+	}
+	file, line := f.FileLine(f.Entry())
+	return fmt.Sprintf("%s (%s:%d)", f.Name(), file, line)
+}
+
+// definitionSource describes where def came from. Pointer definitions are
+// attributed to the caller of the running Fork if current is true.
+func definitionSource(def any, current bool) string {
+	if annotated, ok := def.(_Annotated); ok {
+		def = annotated.Def
+	}
+	switch def := def.(type) {
+	case _ModuleDefinition:
+		ret := fmt.Sprintf("method %s of module %v", def.Method.Name, def.Type)
+		if location := funcLocation(def.Method.Func); location != "" {
+			ret += " at " + location
+		}
+		return ret
+	case _DeclaredProvider:
+		if location := funcLocation(reflect.ValueOf(def.Func)); location != "" {
+			return location
+		}
+		return fmt.Sprintf("provider %v", def.DefType)
+	}
+	v := reflect.ValueOf(def)
+	switch v.Kind() {
+	case reflect.Func:
+		if location := funcLocation(v); location != "" {
+			return location
+		}
+		return fmt.Sprintf("function %v", v.Type())
+	case reflect.Pointer:
+		ret := fmt.Sprintf("pointer %v", v.Type())
+		if current {
+			if location := callerLocation(); location != "" {
+				ret += " passed at " + location
+			}
+		}
+		return ret
+	}
+	return fmt.Sprintf("%T", def)
+}
+
+// valueSource describes where the definition of value came from.
+func valueSource(value _Value) string {
+	if value.initializer == nil {
+		return fmt.Sprintf("definition %v", value.typeInfo.DefType)
+	}
+	return definitionSource(value.initializer.Def, false)
+}
@@ -0,0 +1,124 @@
+package dscope
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testProvenanceLogger string
+
+type testProvenanceModuleA struct {
+	Module
+}
+
+func (testProvenanceModuleA) Logger() testProvenanceLogger {
+	return "a"
+}
+
+type testProvenanceModuleB struct {
+	Module
+}
+
+func (*testProvenanceModuleB) Logger() testProvenanceLogger {
+	return "b"
+}
+
+func recoverError(t *testing.T, fn func()) (err error) {
+	t.Helper()
+	func() {
+		defer func() {
+			p := recover()
+			if p == nil {
+				t.Fatal("should panic")
+			}
+			var ok bool
+			err, ok = p.(error)
+			if !ok {
+				t.Fatalf("got %v", p)
+			}
+		}()
+		fn()
+	}()
+	return
+}
+
+func TestProvenanceDuplicateModules(t *testing.T) {
+	err := recoverError(t, func() {
+		New(new(testProvenanceModuleA), new(testProvenanceModuleB))
+	})
+	if !errors.Is(err, ErrBadDefinition) {
+		t.Fatalf("got %v", err)
+	}
+	msg := err.Error()
+	for _, s := range []string{
+		"method Logger of module dscope.testProvenanceModuleA at github.com/reusee/dscope.testProvenanceModuleA.Logger",
+		"method Logger of module dscope.testProvenanceModuleB at github.com/reusee/dscope.(*testProvenanceModuleB).Logger",
+		"provenance_test.go:",
+	} {
+		if !strings.Contains(msg, s) {
+			t.Fatalf("%q not in %v", s, msg)
+		}
+	}
+}
+
+func TestProvenanceDuplicatePointers(t *testing.T) {
+	err := recoverError(t, func() {
+		New(
+			Provide(testProvenanceLogger("a")),
+			func() testProvenanceLogger { return "b" },
+		)
+	})
+	msg := err.Error()
+	for _, s := range []string{
+		"pointer *dscope.testProvenanceLogger passed at",
+		"provenance_test.go:",
+		"TestProvenanceDuplicatePointers.func1.1",
+	} {
+		if !strings.Contains(msg, s) {
+			t.Fatalf("%q not in %v", s, msg)
+		}
+	}
+}
+
+func TestProvenanceDependencyNotFound(t *testing.T) {
+	scope := New(func(testProvenanceLogger) int { return 1 }, func() testProvenanceLogger { return "" })
+	err := recoverError(t, func() {
+		scope.Fork(func(string) int8 { return 1 })
+	})
+	if !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("got %v", err)
+	}
+	if !strings.Contains(err.Error(), "required by github.com/reusee/dscope.TestProvenanceDependencyNotFound.func") {
+		t.Fatalf("got %v", err)
+	}
+
+}
+
+func TestProvenanceStrictRedefinition(t *testing.T) {
+	scope := New(new(testProvenanceModuleA)).Strict()
+	err := recoverError(t, func() {
+		scope.Fork(func() testProvenanceLogger { return "" })
+	})
+	if !strings.Contains(err.Error(), "inherited from method Logger of module dscope.testProvenanceModuleA") {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestProvenanceLoop(t *testing.T) {
+	type A int
+	type B int
+	err := recoverError(t, func() {
+		New(
+			func(B) A { return 1 },
+			func(A) B { return 1 },
+		)
+	})
+	if !errors.Is(err, ErrDependencyLoop) {
+		t.Fatalf("got %v", err)
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "definitions: ") || !strings.Contains(msg, "TestProvenanceLoop.func1.1") || !strings.Contains(msg, "TestProvenanceLoop.func1.2") {
+		t.Fatalf("got %v", msg)
+	}
+}
@@ -0,0 +1,104 @@
+package dscope
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProviderError(t *testing.T) {
+	type DB int
+	type Repo int
+	type Service int
+	type Handler int
+	errConnect := errors.New("connect")
+	calls := 0
+	scope := New(
+		func() DB {
+			calls++
+			panic(errConnect)
+		},
+		func(db DB) Repo { return Repo(db) },
+		func(repo Repo) Service { return Service(repo) },
+		func(service Service) Handler { return Handler(service) },
+	)
+
+	for i := range 2 {
+		err := recoverError(t, func() {
+			Get[Handler](scope)
+		})
+		var providerErr *ProviderError
+		if !errors.As(err, &providerErr) {
+			t.Fatalf("got %T", err)
+		}
+		if !errors.Is(err, ErrProviderFailed) || !errors.Is(err, errConnect) {
+			t.Fatalf("got %v", err)
+		}
+		if providerErr.DefType != reflect.TypeFor[func() DB]() {
+			t.Fatalf("got %v", providerErr.DefType)
+		}
+		if str := fmt.Sprintf("%v", providerErr.Chain); str != "[dscope.Handler dscope.Service dscope.Repo dscope.DB]" {
+			t.Fatalf("got %v", str)
+		}
+		if providerErr.Value != errConnect {
+			t.Fatalf("got %v", providerErr.Value)
+		}
+		if !strings.Contains(string(providerErr.Stack), "TestProviderError") {
+			t.Fatalf("got %s", providerErr.Stack)
+		}
+		if !strings.Contains(err.Error(), "chain: dscope.Handler <- dscope.Service <- dscope.Repo <- dscope.DB") {
+			t.Fatalf("got %v", err)
+		}
+		// re-invoked on every access
+		if calls != i+1 {
+			t.Fatalf("got %d", calls)
+		}
+	}
+}
+
+func TestProviderErrorDeclaredType(t *testing.T) {
+	type Mode int
+	type Storage string
+	scope := New(
+		func() Mode { return 1 },
+		Select[Storage](
+			func(m Mode) Mode {
+				return m
+			},
+			map[Mode]any{
+				1: func() Storage {
+					panic("unavailable")
+				},
+			},
+		),
+	)
+	err := recoverError(t, func() {
+		Get[Storage](scope)
+	})
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) {
+		t.Fatalf("got %T", err)
+	}
+	// the declared definition type, not the type of the wrapper function
+	if providerErr.DefType != reflect.TypeFor[func(Mode) Storage]() {
+		t.Fatalf("got %v", providerErr.DefType)
+	}
+}
+
+func TestProviderErrorNonError(t *testing.T) {
+	type Foo int
+	err := recoverError(t, func() {
+		Get[Foo](New(func() Foo {
+			panic(42)
+		}))
+	})
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) {
+		t.Fatalf("got %T", err)
+	}
+	if providerErr.Value != 42 || providerErr.Unwrap() != nil {
+		t.Fatalf("got %v", providerErr.Value)
+	}
+}
@@ -0,0 +1,119 @@
+package dscope
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+const TheoryOfScopePruning = `
+dscope pruning theory:
+- Prune returns a child scope holding only the definitions that resolving
+  the given roots needs: the dependency closure of the root types, or of the
+  parameters of root functions, in the order of InitOrder.
+- Kept values keep their initializers and cached results, since their
+  dependencies are unchanged. Values sharing an initializer with a kept value
+  are kept too, because the initializer computes all of them at once.
+- The types a kept module requires or exports are roots too, with their
+  closures, so forks of the child scope still satisfy module contracts.
+- A definition depending on an opaque built-in may resolve any type at
+  runtime. If the closure contains one, nothing can be pruned safely and the
+  scope is returned unchanged.
+- Configured modules are kept, as by Without, so children redefining a
+  configuration type discover their methods again.
+- Resolvers are kept; values they resolved lazily are resolved again in the
+  child scope, as after Without.
+- The child scope has its own signature, derived from the parent signature
+  and the kept types, so forks of it never share cached forkers with forks of
+  the parent. A value kept without the other outputs of its definition does
+  not make forks of the child share forkers with forks of a scope providing
+  them, since Fork signatures cover the provided types.
+`
+
+// Prune returns a child scope holding only the definitions needed to resolve
+// roots, which are types or functions whose parameters are resolved. It
+// panics with a DependencyNotFoundError if a needed type has no definition.
+func (scope Scope) Prune(roots ...any) Scope {
+	var rootTypes []reflect.Type
+	for _, root := range roots {
+		switch root := root.(type) {
+		case nil:
+			panic(&BadArgumentError{
+				Reason: "nil root",
+			})
+		case reflect.Type:
+			rootTypes = append(rootTypes, root)
+		default:
+			fnType := validateCallableValue(reflect.ValueOf(root))
+			for i := range fnType.NumIn() {
+				rootTypes = append(rootTypes, fnType.In(i))
+			}
+		}
+	}
+
+	kept := make(map[_TypeID]struct{})
+	keptInitializers := make(map[int64]struct{})
+	for len(rootTypes) > 0 {
+		for _, id := range scope.initOrder(rootTypes) {
+			if isOpaqueDependency(id) {
+				return scope
+			}
+			kept[id] = struct{}{}
+		}
+		rootTypes = nil
+		for value := range scope.values.IterValues() {
+			if _, ok := kept[value.typeInfo.TypeID]; !ok {
+				continue
+			}
+			keptInitializers[value.initializer.ID] = struct{}{}
+			// contracts of kept modules are checked by forks of the child scope
+			if module := value.typeInfo.Module; module != nil {
+				for _, t := range slices.Concat(module.Requires, module.Exports) {
+					id := getTypeID(t)
+					if _, ok := kept[id]; !ok && !isAlwaysProvided(id) {
+						rootTypes = append(rootTypes, t)
+					}
+				}
+			}
+		}
+	}
+	var values []_Value
+	for value := range scope.values.IterValues() {
+		if _, ok := keptInitializers[value.initializer.ID]; !ok {
+			continue
+		}
+		values = append(values, value)
+	}
+	slices.SortFunc(values, func(a, b _Value) int {
+		return cmp.Compare(a.typeInfo.TypeID, b.typeInfo.TypeID)
+	})
+
+	h := sha256.New()
+	h.Write(scope.signature[:])
+	h.Write([]byte("prune"))
+	buf := make([]byte, 0, len(values)*8)
+	for _, value := range values {
+		buf = binary.NativeEndian.AppendUint64(buf, uint64(value.typeInfo.TypeID))
+	}
+	if _, err := h.Write(buf); err != nil {
+		panic(fmt.Errorf("unexpected error during signature hash calculation in Scope.Prune: %w", err))
+	}
+	var signature _Hash
+	h.Sum(signature[:0])
+
+	return Scope{
+		values: &_StackedMap{
+			Values: values,
+			Height: 1,
+		},
+		signature:   signature,
+		forkFuncKey: scope.forkFuncKey,
+		resolvers:   scope.resolvers.reset(),
+		strict:      scope.strict,
+		resolving:   scope.resolving,
+		modules:     scope.modules,
+	}
+}
@@ -0,0 +1,245 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPrune(t *testing.T) {
+	type A int
+	type B int
+	type C int
+	type Unrelated int
+	evaluated := 0
+	scope := New(
+		func() A {
+			evaluated++
+			return 1
+		},
+		func(a A) (B, C) {
+			return B(a), C(a)
+		},
+		func() Unrelated {
+			return 1
+		},
+	)
+	Get[A](scope)
+
+	pruned := scope.Prune(reflect.TypeFor[B]())
+	types := make(map[reflect.Type]bool)
+	for definition := range pruned.Definitions() {
+		types[definition.Type] = true
+	}
+	// C shares the initializer of B
+	if len(types) != 3 || !types[reflect.TypeFor[A]()] || !types[reflect.TypeFor[B]()] || !types[reflect.TypeFor[C]()] {
+		t.Fatalf("got %v", types)
+	}
+	if err := recoverError(t, func() {
+		Get[Unrelated](pruned)
+	}); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("got %v", err)
+	}
+
+	// cached values are shared
+	if b := Get[B](pruned); b != 1 {
+		t.Fatalf("got %v", b)
+	}
+	if evaluated != 1 {
+		t.Fatalf("got %v", evaluated)
+	}
+
+	// function roots
+	pruned = scope.Prune(func(a A) {})
+	n := 0
+	for range pruned.Definitions() {
+		n++
+	}
+	if n != 1 {
+		t.Fatalf("got %v", n)
+	}
+}
+
+func TestPruneFork(t *testing.T) {
+	type A int
+	type B int
+	type Unrelated int
+	scope := New(
+		func() A {
+			return 1
+		},
+		func() Unrelated {
+			return 2
+		},
+	)
+	pruned := scope.Prune(reflect.TypeFor[A]())
+
+	forked := pruned.Fork(func(a A) B {
+		return B(a) + 1
+	})
+	if b := Get[B](forked); b != 2 {
+		t.Fatalf("got %v", b)
+	}
+
+	// forks do not share forkers with forks of the parent
+	def := func(u Unrelated) B {
+		return B(u)
+	}
+	if b := Get[B](scope.Fork(def)); b != 2 {
+		t.Fatalf("got %v", b)
+	}
+	if err := recoverError(t, func() {
+		pruned.Fork(def)
+	}); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestPrunePartialDefinition(t *testing.T) {
+	type A int
+	type B int
+	type C int
+	type E int
+	base := New(func() (A, B) {
+		return 1, 2
+	})
+	def := func(a A) E {
+		return E(a)
+	}
+
+	// warm the forker cache from the full scope
+	if e := Get[E](base.Fork(func() C { return 3 }).Fork(def)); e != 1 {
+		t.Fatalf("got %v", e)
+	}
+
+	// B is kept from func() (A, B) without A
+	pruned := base.Fork(func() A { return 4 }).Prune(reflect.TypeFor[B]())
+	if b := Get[B](pruned); b != 2 {
+		t.Fatalf("got %v", b)
+	}
+	child := pruned.Fork(func() C { return 3 })
+	if err := recoverError(t, func() {
+		child.Fork(def)
+	}); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestPruneOpaque(t *testing.T) {
+	type A int
+	type Unrelated int
+	scope := New(
+		func(scope Scope) A {
+			return 1
+		},
+		func() Unrelated {
+			return 2
+		},
+	)
+	pruned := scope.Prune(reflect.TypeFor[A]())
+	if u := Get[Unrelated](pruned); u != 2 {
+		t.Fatalf("got %v", u)
+	}
+}
+
+func TestPrunePrivate(t *testing.T) {
+	scope := New(new(testPrivateModule)).Fork(func() int {
+		return 42
+	})
+	pruned := scope.Prune(reflect.TypeFor[testPrivateService]())
+	if s := Get[testPrivateService](pruned); s != Get[testPrivateService](scope) {
+		t.Fatalf("got %v", s)
+	}
+	if _, ok := pruned.Get(reflect.TypeFor[int]()); ok {
+		t.Fatal("should be pruned")
+	}
+}
+
+func TestPruneBadArguments(t *testing.T) {
+	type A int
+	for _, fn := range []func(){
+		func() { New().Prune(nil) },
+		func() { New().Prune(42) },
+	} {
+		if err := recoverError(t, fn); !errors.Is(err, ErrBadArgument) {
+			t.Fatalf("got %v", err)
+		}
+	}
+	if err := recoverError(t, func() {
+		New().Prune(reflect.TypeFor[A]())
+	}); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+type testPruneConfig string
+
+type testPruneConfigSource string
+
+type testPruneService string
+
+type testPruneOther string
+
+type testPruneContractModule struct {
+	Module
+}
+
+func (testPruneContractModule) Requires() []reflect.Type {
+	return []reflect.Type{
+		reflect.TypeFor[testPruneConfig](),
+	}
+}
+
+func (testPruneContractModule) Exports() []reflect.Type {
+	return []reflect.Type{
+		reflect.TypeFor[testPruneService](),
+		reflect.TypeFor[testPruneOther](),
+	}
+}
+
+func (testPruneContractModule) Service() testPruneService {
+	return "service"
+}
+
+func (testPruneContractModule) Other() testPruneOther {
+	return "other"
+}
+
+func TestPruneModuleContracts(t *testing.T) {
+	type Unrelated int
+	scope := New(
+		new(testPruneContractModule),
+		func(s testPruneConfigSource) testPruneConfig {
+			return testPruneConfig(s)
+		},
+		Provide(testPruneConfigSource("source")),
+		Provide(Unrelated(1)),
+	)
+	pruned := scope.Prune(reflect.TypeFor[testPruneService]())
+	types := make(map[reflect.Type]bool)
+	for definition := range pruned.Definitions() {
+		types[definition.Type] = true
+	}
+	// required and exported types are kept with their closures
+	for _, typ := range []reflect.Type{
+		reflect.TypeFor[testPruneService](),
+		reflect.TypeFor[testPruneOther](),
+		reflect.TypeFor[testPruneConfig](),
+		reflect.TypeFor[testPruneConfigSource](),
+	} {
+		if !types[typ] {
+			t.Fatalf("%v should be kept: %v", typ, types)
+		}
+	}
+	if types[reflect.TypeFor[Unrelated]()] {
+		t.Fatal("should be pruned")
+	}
+
+	// forks of the pruned scope satisfy the contracts
+	forked := pruned.Fork(func(s testPruneService) string {
+		return string(s)
+	})
+	if s := Get[string](forked); s != "service" {
+		t.Fatalf("got %v", s)
+	}
+}
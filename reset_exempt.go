@@ -0,0 +1,23 @@
+package dscope
+
+const TheoryOfResetExemption = `
+dscope reset exemption theory:
+- ResetExempt marks a definition, a module or a conditional group whose
+  initializers survive Scope.Reset, for expensive stateless values such as
+  connection pools.
+- An exempt initializer is kept only while none of its dependencies is reset.
+  Dependencies that are themselves exempt and kept, or pointer definitions, do
+  not count as reset; opaque dependencies such as Scope or Fork always do.
+- The decision is made by the lazy reset layer on first access and cached with
+  the layer, so materialising the layer when forking from a reset scope keeps
+  the same initializers.
+- Exemption only concerns Reset. A Fork redefining a dependency still
+  re-evaluates the definition.
+- The mark is part of the Fork cache key.
+`
+
+// ResetExempt marks def, a definition, module or conditional definition, as
+// exempt from Scope.Reset.
+func ResetExempt(def any) any {
+	return annotate(def, defResetExempt)
+}
@@ -0,0 +1,138 @@
+package dscope
+
+import (
+	"testing"
+)
+
+func TestResetExempt(t *testing.T) {
+	type Pool int
+	type Request int
+	type Handler int
+	var pools, requests, handlers int
+	scope := New(
+		ResetExempt(func() Pool {
+			pools++
+			return Pool(pools)
+		}),
+		func() Request {
+			requests++
+			return Request(requests)
+		},
+		func(p Pool, r Request) Handler {
+			handlers++
+			return Handler(handlers)
+		},
+	)
+	Get[Handler](scope)
+
+	reset := scope.Reset()
+	Get[Handler](reset)
+	if pools != 1 {
+		t.Fatalf("exempt definition re-evaluated: %d", pools)
+	}
+	if requests != 2 || handlers != 2 {
+		t.Fatalf("got %d %d", requests, handlers)
+	}
+
+	// forking from a reset scope materialises the lazy layer
+	child := reset.Reset().Fork(func() string { return "foo" })
+	Get[Pool](child)
+	Get[Request](child)
+	if pools != 1 {
+		t.Fatalf("exempt definition re-evaluated after materialisation: %d", pools)
+	}
+	if requests != 3 {
+		t.Fatalf("got %d", requests)
+	}
+
+	// a fork redefining nothing the pool depends on keeps it
+	if Get[Pool](scope.Fork(func() Request { return 42 })) != 1 {
+		t.Fatal()
+	}
+}
+
+func TestResetExemptResetDependency(t *testing.T) {
+	type Config int
+	type Pool int
+	var pools int
+	scope := New(
+		func() Config { return 1 },
+		ResetExempt(func(Config) Pool {
+			pools++
+			return Pool(pools)
+		}),
+	)
+	Get[Pool](scope)
+	Get[Pool](scope.Reset())
+	if pools != 2 {
+		t.Fatalf("exempt definition with reset dependency kept: %d", pools)
+	}
+}
+
+func TestResetExemptDependencies(t *testing.T) {
+	type Config int
+	type Pool int
+	type Client int
+	var pools, clients int
+	scope := New(
+		Provide(Config(1)),
+		ResetExempt(func(Config) Pool {
+			pools++
+			return Pool(pools)
+		}),
+		ResetExempt(func(Pool) Client {
+			clients++
+			return Client(clients)
+		}),
+	)
+	Get[Client](scope)
+	Get[Client](scope.Reset())
+	if pools != 1 || clients != 1 {
+		t.Fatalf("got %d %d", pools, clients)
+	}
+}
+
+func TestResetExemptOpaqueDependency(t *testing.T) {
+	type Pool int
+	var pools int
+	scope := New(
+		ResetExempt(func(Scope) Pool {
+			pools++
+			return Pool(pools)
+		}),
+	)
+	Get[Pool](scope)
+	Get[Pool](scope.Reset())
+	if pools != 2 {
+		t.Fatalf("exempt definition with opaque dependency kept: %d", pools)
+	}
+}
+
+type testResetExemptModule struct {
+	Module
+}
+
+func (testResetExemptModule) Pool(counter *int) int8 {
+	*counter++
+	return int8(*counter)
+}
+
+func TestResetExemptModule(t *testing.T) {
+	counter := new(int)
+	scope := New(
+		Provide(counter),
+		ResetExempt(new(testResetExemptModule)),
+	)
+	Get[int8](scope)
+	Get[int8](scope.Reset())
+	if *counter != 1 {
+		t.Fatalf("got %d", *counter)
+	}
+}
+
+func TestResetExemptCacheKey(t *testing.T) {
+	fn := func() int { return 1 }
+	if New(fn).forkFuncKey == New(ResetExempt(fn)).forkFuncKey {
+		t.Fatal("cache key should cover the mark")
+	}
+}
@@ -0,0 +1,87 @@
+package dscope
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+const TheoryOfPartialReset = `
+dscope partial reset theory:
+- ResetTypes returns a new scope in which only the given types and the values
+  depending on them, directly or transitively, are recomputed on next access.
+  Every other value keeps its cached result, as Fork does for values unaffected
+  by overrides.
+- Values depending on opaque dependencies (InjectStruct, Fork, Reset, Scope,
+  Call) may reach any type at runtime and are always recomputed.
+- Values sharing an initializer with a recomputed value are recomputed with it.
+- Lazily resolved values are resolved again only if they depend on a
+  recomputed value, directly or transitively, or on a type not resolved yet.
+- The original scope is unaffected, and the structure of the scope, including
+  its signature, is unchanged.
+`
+
+// ResetTypes returns a new Scope in which values of the given types, and every
+// value depending on them, will be recomputed the next time they are requested.
+// It panics if a type has no definition.
+func (scope Scope) ResetTypes(types ...reflect.Type) Scope {
+	seeds := make(map[_TypeID]struct{}, len(types))
+	for _, t := range types {
+		if t == nil {
+			panic(&BadArgumentError{
+				Reason: "nil type",
+			})
+		}
+		id := getTypeID(t)
+		if isAlwaysProvided(id) {
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("cannot reset built-in %v", t),
+			})
+		}
+		if _, ok := scope.values.Load(id); !ok {
+			throwErrDependencyNotFound(scope, t)
+		}
+		seeds[id] = struct{}{}
+	}
+
+	values := dependentClosure(scope.values, seeds)
+	if len(values) == 0 {
+		return scope
+	}
+	slices.SortFunc(values, func(a, b _Value) int {
+		return cmp.Compare(a.typeInfo.TypeID, b.typeInfo.TypeID)
+	})
+	resetIDs := make(map[_TypeID]struct{}, len(values))
+	resetInitializers := make(map[int64]*_Initializer) // Share reset initializers for multi-return
+	for i, value := range values {
+		resetIDs[value.typeInfo.TypeID] = struct{}{}
+		initID := value.initializer.ID
+		resetInit, ok := resetInitializers[initID]
+		if !ok {
+			resetInit = value.initializer.reset()
+			resetInitializers[initID] = resetInit
+		}
+		values[i].initializer = resetInit
+	}
+
+	stack := scope.values
+	if stack.Height > maxStackHeight {
+		stack = stack.flatten()
+	}
+	return Scope{
+		values:      stack.Append(values),
+		signature:   scope.signature,
+		forkFuncKey: scope.forkFuncKey,
+		resolvers:   scope.resolvers.resetTypes(scope.values, resetIDs),
+		strict:      scope.strict,
+		resolving:   scope.resolving,
+		modules:     scope.modules,
+	}
+}
+
+// ResetType returns a new Scope in which the value of type T and every value
+// depending on it will be recomputed the next time they are requested.
+func ResetType[T any](scope Scope) Scope {
+	return scope.ResetTypes(reflect.TypeFor[T]())
+}
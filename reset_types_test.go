@@ -0,0 +1,186 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResetTypes(t *testing.T) {
+	type Token int
+	type Client int
+	type Config int
+	type Handler int
+	var tokens, clients, configs, handlers int
+	scope := New(
+		func() Token {
+			tokens++
+			return Token(tokens)
+		},
+		func(t Token) Client {
+			clients++
+			return Client(t)
+		},
+		func() Config {
+			configs++
+			return Config(configs)
+		},
+		func(c Client, cfg Config) Handler {
+			handlers++
+			return Handler(c)
+		},
+	)
+	if h := Get[Handler](scope); h != 1 {
+		t.Fatalf("got %d", h)
+	}
+
+	reset := ResetType[Token](scope)
+	if h := Get[Handler](reset); h != 2 {
+		t.Fatalf("got %d", h)
+	}
+	Get[Config](reset)
+	if tokens != 2 || clients != 2 || handlers != 2 {
+		t.Fatalf("got %d %d %d", tokens, clients, handlers)
+	}
+	if configs != 1 {
+		t.Fatalf("unrelated value recomputed: %d", configs)
+	}
+
+	// original scope is unaffected
+	if h := Get[Handler](scope); h != 1 {
+		t.Fatalf("got %d", h)
+	}
+	if reset.signature != scope.signature {
+		t.Fatal("signature should not change")
+	}
+}
+
+func TestResetTypesOpaqueDependency(t *testing.T) {
+	type Token int
+	type Service int
+	var tokens, services int
+	scope := New(
+		func() Token {
+			tokens++
+			return Token(tokens)
+		},
+		func(s Scope) Service {
+			services++
+			return Service(services)
+		},
+		func() string {
+			return "foo"
+		},
+	)
+	Get[Service](scope)
+	Get[Service](scope.ResetTypes(reflect.TypeFor[string]()))
+	if services != 2 {
+		t.Fatalf("opaque consumer not recomputed: %d", services)
+	}
+}
+
+func TestResetTypesSharedInitializer(t *testing.T) {
+	type A int
+	type B int
+	type C int
+	var calls, cs int
+	scope := New(
+		func() (A, B) {
+			calls++
+			return A(calls), B(calls)
+		},
+		func(b B) C {
+			cs++
+			return C(b)
+		},
+	)
+	Get[C](scope)
+	reset := ResetType[A](scope)
+	if c := Get[C](reset); c != 2 {
+		t.Fatalf("got %d", c)
+	}
+	if calls != 2 || cs != 2 {
+		t.Fatalf("got %d %d", calls, cs)
+	}
+}
+
+func TestResetTypesDeepStack(t *testing.T) {
+	scope := New(func() int { return 1 })
+	for range maxStackHeight * 2 {
+		scope = ResetType[int](scope)
+	}
+	if scope.values.Height > maxStackHeight+1 {
+		t.Fatalf("stack not flattened: %d", scope.values.Height)
+	}
+	if Get[int](scope) != 1 {
+		t.Fatal()
+	}
+}
+
+func TestResetTypesResolvers(t *testing.T) {
+	type A int
+	type B int
+	type Dependent int
+	type Independent int
+	var dependentCalls, independentCalls int
+	scope := New(
+		func() A {
+			return 1
+		},
+		func() B {
+			return 2
+		},
+		Resolver(func(t reflect.Type) (any, bool) {
+			switch t {
+			case reflect.TypeFor[Dependent]():
+				return func(a A) Dependent {
+					dependentCalls++
+					return Dependent(a)
+				}, true
+			case reflect.TypeFor[Independent]():
+				return func(b B) Independent {
+					independentCalls++
+					return Independent(b)
+				}, true
+			}
+			return nil, false
+		}),
+	)
+	Get[Dependent](scope)
+	Get[Independent](scope)
+
+	reset := ResetType[A](scope)
+	Get[Dependent](reset)
+	Get[Independent](reset)
+	if dependentCalls != 2 {
+		t.Fatalf("got %v", dependentCalls)
+	}
+	if independentCalls != 1 {
+		t.Fatalf("got %v", independentCalls)
+	}
+}
+
+func TestResetTypesBadArgument(t *testing.T) {
+	scope := New(func() int { return 1 })
+	for _, c := range []struct {
+		types []reflect.Type
+		err   error
+	}{
+		{[]reflect.Type{nil}, ErrBadArgument},
+		{[]reflect.Type{reflect.TypeFor[Scope]()}, ErrBadArgument},
+		{[]reflect.Type{reflect.TypeFor[string]()}, ErrDependencyNotFound},
+	} {
+		func() {
+			defer func() {
+				p := recover()
+				if p == nil {
+					t.Fatal("should panic")
+				}
+				if err, ok := p.(error); !ok || !errors.Is(err, c.err) {
+					t.Fatalf("got %v", p)
+				}
+			}()
+			scope.ResetTypes(c.types...)
+		}()
+	}
+}
@@ -0,0 +1,271 @@
+package dscope
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+const TheoryOfResolvers = `
+dscope resolver theory:
+- A Resolver supplies definitions for types that have no explicit definition,
+  such as instantiations of a generic family that cannot be enumerated up front.
+- Resolvers are registered by passing them to Fork and are inherited by child
+  scopes. Resolvers registered in a later Fork take precedence over inherited
+  ones; within one Fork, earlier arguments take precedence. The first resolver
+  claiming a type wins.
+- During Fork, dependencies of the new definitions that the scope cannot satisfy
+  are offered to the resolvers, transitively, and the resolved definitions join
+  the fork like explicit definitions: they take part in the cache key,
+  dependency analysis and reset analysis.
+- Get and Call offer unknown types to the resolvers lazily. A lazily resolved
+  definition is cached per scope, so its provider still runs at most once, and
+  it is listed by AllTypes once materialized. Child and reset scopes resolve
+  such types again.
+- A resolved definition must provide the claimed type. Resolvers may return
+  DynamicProvider definitions.
+`
+
+// Resolver returns a definition, a provider function or a pointer, for a type
+// without an explicit definition. ok reports whether the resolver claims t.
+type Resolver func(t reflect.Type) (def any, ok bool)
+
+// _Resolvers holds the resolvers of a scope and the definitions they resolved
+// lazily.
+type _Resolvers struct {
+	Funcs    []Resolver           // In precedence order.
+	Excluded map[_TypeID]struct{} // Types removed by Scope.Without, never resolved. Shared, read-only.
+	Values   sync.Map             // _TypeID -> _Value
+}
+
+func newResolvers(funcs []Resolver, excluded map[_TypeID]struct{}) *_Resolvers {
+	if len(funcs) == 0 {
+		return nil
+	}
+	return &_Resolvers{
+		Funcs:    funcs,
+		Excluded: excluded,
+	}
+}
+
+// funcs returns the resolvers in precedence order. It is safe to call on nil.
+func (r *_Resolvers) funcs() []Resolver {
+	if r == nil {
+		return nil
+	}
+	return r.Funcs
+}
+
+// reset returns resolvers with the same funcs and no lazily resolved values.
+func (r *_Resolvers) reset() *_Resolvers {
+	if r == nil {
+		return nil
+	}
+	return newResolvers(r.Funcs, r.Excluded)
+}
+
+// resetTypes returns resolvers with the same funcs, keeping the lazily
+// resolved values of r that depend on none of the reset types, transitively.
+// Values depending on opaque dependencies or on types not yet resolved are
+// not kept.
+func (r *_Resolvers) resetTypes(values *_StackedMap, reset map[_TypeID]struct{}) *_Resolvers {
+	if r == nil {
+		return nil
+	}
+	resolved := make(map[_TypeID]_Value)
+	r.Values.Range(func(key, value any) bool {
+		resolved[key.(_TypeID)] = value.(_Value)
+		return true
+	})
+
+	affected := make(map[_TypeID]bool)
+	var isAffected func(id _TypeID) bool
+	isAffected = func(id _TypeID) bool {
+		if ret, ok := affected[id]; ok {
+			return ret
+		}
+		affected[id] = true // conservative on loops
+		ret := false
+		for _, depID := range resolved[id].typeInfo.Dependencies {
+			if isOpaqueDependency(depID) {
+				ret = true
+			} else if _, ok := reset[depID]; ok {
+				ret = true
+			} else if _, ok := values.Load(depID); ok || isAlwaysProvided(depID) {
+				continue
+			} else if _, ok := resolved[depID]; ok {
+				ret = isAffected(depID)
+			} else {
+				ret = true
+			}
+			if ret {
+				break
+			}
+		}
+		affected[id] = ret
+		return ret
+	}
+
+	// values sharing an initializer are resolved again together
+	affectedInitializers := make(map[int64]struct{})
+	for id, value := range resolved {
+		if isAffected(id) {
+			affectedInitializers[value.initializer.ID] = struct{}{}
+		}
+	}
+	ret := r.reset()
+	for id, value := range resolved {
+		if _, ok := affectedInitializers[value.initializer.ID]; ok {
+			continue
+		}
+		ret.Values.Store(id, value)
+	}
+	return ret
+}
+
+// excluded returns the types never resolved. It is safe to call on nil.
+func (r *_Resolvers) excluded() map[_TypeID]struct{} {
+	if r == nil {
+		return nil
+	}
+	return r.Excluded
+}
+
+// resolve asks the resolvers for a definition of t.
+func (r *_Resolvers) resolve(t reflect.Type) (def any, ok bool) {
+	if _, ok := r.excluded()[getTypeID(t)]; ok {
+		return nil, false
+	}
+	for _, fn := range r.funcs() {
+		def, ok = fn(t)
+		if !ok {
+			continue
+		}
+		if def == nil {
+			panic(errors.Join(
+				fmt.Errorf("resolver returned nil definition for %v", t),
+				ErrBadDefinition,
+			))
+		}
+		if provider, ok := def.(DynamicProvider); ok {
+			def = provider.definition()
+		}
+		if !slices.Contains(definitionOutputTypes(def), t) {
+			panic(errors.Join(
+				fmt.Errorf("resolver returned %T for %v, which does not provide it", def, t),
+				ErrBadDefinition,
+			))
+		}
+		return def, true
+	}
+	return nil, false
+}
+
+// load returns the lazily resolved value for id, resolving it on first access.
+func (r *_Resolvers) load(scope Scope, id _TypeID) (ret _Value, ok bool) {
+	if v, ok := r.Values.Load(id); ok {
+		return v.(_Value), true
+	}
+	def, ok := r.resolve(typeIDToType(id))
+	if !ok {
+		return ret, false
+	}
+
+	defType := definitionType(def)
+	var initializer *_Initializer
+	var dependencies []_TypeID
+	if defType.Kind() == reflect.Pointer {
+		initializer = newInitializer(def, true)
+	} else {
+		initializer = newInitializer(def, false)
+		for i := range defType.NumIn() {
+			dependencies = append(dependencies, getTypeID(defType.In(i)))
+		}
+	}
+	for i, t := range definitionOutputTypes(def) {
+		outputID := getTypeID(t)
+		if outputID != id {
+			if _, ok := scope.values.Load(outputID); ok || isAlwaysProvided(outputID) {
+				// explicit definitions take precedence
+				continue
+			}
+		}
+		value := _Value{
+			typeInfo: &_TypeInfo{
+				TypeID:       outputID,
+				DefType:      defType,
+				Position:     i,
+				Dependencies: dependencies,
+			},
+			initializer: initializer,
+		}
+		actual, _ := r.Values.LoadOrStore(outputID, value)
+		if outputID == id {
+			ret = actual.(_Value)
+		}
+	}
+	return ret, true
+}
+
+// types returns the lazily resolved types, sorted by TypeID.
+func (r *_Resolvers) types() (ret []_TypeID) {
+	if r == nil {
+		return nil
+	}
+	r.Values.Range(func(key, _ any) bool {
+		ret = append(ret, key.(_TypeID))
+		return true
+	})
+	slices.SortFunc(ret, cmp.Compare)
+	return
+}
+
+// resolveDefinitions appends the definitions resolved for dependencies of defs
+// that neither the scope nor defs provide, transitively.
+func resolveDefinitions(scope Scope, defs []any, resolvers *_Resolvers) []any {
+	provided := make(map[_TypeID]bool)
+	for _, def := range defs {
+		for _, t := range definitionOutputTypes(def) {
+			provided[definitionTypeID(def, t)] = true
+		}
+	}
+	var queue []_TypeID
+	for _, def := range defs {
+		for _, t := range definitionInputTypes(def) {
+			queue = append(queue, definitionTypeID(def, t))
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if provided[id] || isAlwaysProvided(id) {
+			continue
+		}
+		if _, ok := privateTypeModule(id); ok {
+			// private types are never resolved
+			continue
+		}
+		t := typeIDToType(id)
+		provided[id] = true
+		if _, ok := scope.values.Load(id); ok {
+			continue
+		}
+		def, ok := resolvers.resolve(t)
+		if !ok {
+			// reported by dependency analysis
+			continue
+		}
+		// do not modify the caller's slice
+		defs = append(defs[:len(defs):len(defs)], def)
+		for _, t := range definitionOutputTypes(def) {
+			provided[getTypeID(t)] = true
+		}
+		for _, t := range definitionInputTypes(def) {
+			queue = append(queue, getTypeID(t))
+		}
+	}
+	return defs
+}
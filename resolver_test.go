@@ -0,0 +1,194 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testResolverRepo[T any] struct {
+	Name string
+}
+
+type testResolverUser struct{}
+
+type testResolverOrder struct{}
+
+type testResolverRepoMarker interface {
+	repoName() string
+}
+
+func (r testResolverRepo[T]) repoName() string {
+	return r.Name
+}
+
+func testResolverRepos(calls *int) Resolver {
+	return func(t reflect.Type) (any, bool) {
+		if !t.Implements(reflect.TypeFor[testResolverRepoMarker]()) ||
+			!strings.HasPrefix(t.Name(), "testResolverRepo[") {
+			return nil, false
+		}
+		return reflect.MakeFunc(
+			reflect.FuncOf([]reflect.Type{reflect.TypeFor[string]()}, []reflect.Type{t}, false),
+			func(args []reflect.Value) []reflect.Value {
+				*calls++
+				ret := reflect.New(t).Elem()
+				ret.FieldByName("Name").Set(args[0])
+				return []reflect.Value{ret}
+			},
+		).Interface(), true
+	}
+}
+
+func TestResolverStatic(t *testing.T) {
+	type Service struct {
+		Users  testResolverRepo[testResolverUser]
+		Orders testResolverRepo[testResolverOrder]
+	}
+	var calls int
+	scope := New(
+		testResolverRepos(&calls),
+		Provide("db"),
+		func(
+			users testResolverRepo[testResolverUser],
+			orders testResolverRepo[testResolverOrder],
+		) Service {
+			return Service{
+				Users:  users,
+				Orders: orders,
+			}
+		},
+	)
+
+	// resolved during Fork
+	if _, ok := scope.values.Load(getTypeID(reflect.TypeFor[testResolverRepo[testResolverUser]]())); !ok {
+		t.Fatal("resolved definition not added during Fork")
+	}
+
+	s := Get[Service](scope)
+	if s.Users.Name != "db" || s.Orders.Name != "db" {
+		t.Fatalf("got %+v", s)
+	}
+	Get[Service](scope)
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+
+	// resolved definitions take part in reset analysis
+	child := scope.Fork(Provide("db2"))
+	if s := Get[Service](child); s.Users.Name != "db2" {
+		t.Fatalf("got %+v", s)
+	}
+}
+
+func TestResolverLazy(t *testing.T) {
+	var calls int
+	scope := New(
+		testResolverRepos(&calls),
+		Provide("db"),
+	)
+	found := false
+	for typ := range scope.AllTypes() {
+		if typ == reflect.TypeFor[testResolverRepo[testResolverUser]]() {
+			found = true
+		}
+	}
+	if found {
+		t.Fatal("type listed before materialization")
+	}
+
+	repo := Get[testResolverRepo[testResolverUser]](scope)
+	if repo.Name != "db" {
+		t.Fatalf("got %+v", repo)
+	}
+	Get[testResolverRepo[testResolverUser]](scope)
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+
+	for typ := range scope.AllTypes() {
+		if typ == reflect.TypeFor[testResolverRepo[testResolverUser]]() {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("materialized type not listed")
+	}
+
+	scope.Call(func(repo testResolverRepo[testResolverOrder]) {
+		if repo.Name != "db" {
+			t.Fatalf("got %+v", repo)
+		}
+	})
+
+	// reset scopes resolve again
+	Get[testResolverRepo[testResolverUser]](scope.Reset())
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+
+	if _, ok := scope.Get(reflect.TypeFor[int]()); ok {
+		t.Fatal("unclaimed type should not be found")
+	}
+}
+
+func TestResolverPrecedence(t *testing.T) {
+	first := Resolver(func(t reflect.Type) (any, bool) {
+		if t != reflect.TypeFor[int]() {
+			return nil, false
+		}
+		return func() int { return 1 }, true
+	})
+	second := Resolver(func(t reflect.Type) (any, bool) {
+		if t != reflect.TypeFor[int]() {
+			return nil, false
+		}
+		return func() int { return 2 }, true
+	})
+	scope := New(first, second)
+	if i := Get[int](scope); i != 1 {
+		t.Fatalf("got %d", i)
+	}
+	// later forks take precedence
+	if i := Get[int](scope.Fork(second)); i != 2 {
+		t.Fatalf("got %d", i)
+	}
+	// inherited resolvers
+	if i := Get[int](scope.Fork()); i != 1 {
+		t.Fatalf("got %d", i)
+	}
+	// explicit definitions take precedence
+	if i := Get[int](scope.Fork(Provide(3))); i != 3 {
+		t.Fatalf("got %d", i)
+	}
+}
+
+func TestResolverBadDefinition(t *testing.T) {
+	scope := New(Resolver(func(t reflect.Type) (any, bool) {
+		return Provide("foo"), true
+	}))
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		if err, ok := p.(error); !ok || !errors.Is(err, ErrBadDefinition) {
+			t.Fatalf("got %v", p)
+		}
+	}()
+	Get[int](scope)
+}
+
+func TestResolverNil(t *testing.T) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		if err, ok := p.(error); !ok || !errors.Is(err, ErrBadArgument) {
+			t.Fatalf("got %v", p)
+		}
+	}()
+	New(Resolver(nil))
+}
@@ -0,0 +1,18 @@
+package dscope
+
+import "reflect"
+
+const TheoryOfScopeValue = `
+dscope scope value theory:
+- The Scope type itself is always provided as a built-in dependency, bound to
+  the scope that resolves it, so providers and call targets can receive the
+  current scope without forking an empty child.
+- Like other always-provided types, user definitions for Scope are ignored in
+  favor of the built-in binding to the current scope.
+- Scope is opaque to dependency analysis: a provider receiving it can resolve
+  any type. Providers depending on Scope are therefore pessimistically
+  re-evaluated whenever a scope is forked with new definitions, mirroring the
+  InjectStruct, Fork and Reset treatment.
+`
+
+var scopeTypeID = getTypeID(reflect.TypeFor[Scope]())
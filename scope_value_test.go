@@ -0,0 +1,94 @@
+package dscope
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetScopeValue(t *testing.T) {
+	scope := New(func() int {
+		return 42
+	})
+	s := Get[Scope](scope)
+	if Get[int](s) != 42 {
+		t.Fatal("bound scope did not resolve definitions")
+	}
+	if s.signature != scope.signature {
+		t.Fatal("bound scope differs from current scope")
+	}
+}
+
+func TestCallWithScopeDependency(t *testing.T) {
+	type Service int
+	scope := New(
+		func() int {
+			return 42
+		},
+		func(s Scope) Service {
+			return Service(Get[int](s))
+		},
+	)
+	if s := Get[Service](scope); s != 42 {
+		t.Fatalf("got %d", s)
+	}
+	scope.Call(func(s Scope) {
+		if Get[Service](s) != 42 {
+			t.Fatal()
+		}
+	})
+}
+
+func TestScopeValueIgnoredDefinition(t *testing.T) {
+	scope := New(
+		func() int {
+			return 42
+		},
+		func() Scope {
+			return New()
+		},
+	)
+	if Get[int](Get[Scope](scope)) != 42 {
+		t.Fatal("user-provided Scope definition was used instead of built-in")
+	}
+	var count int
+	for typ := range scope.AllTypes() {
+		if typ == reflect.TypeFor[Scope]() {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Fatalf("Scope should appear exactly once in AllTypes, got %d", count)
+	}
+}
+
+func TestScopeDependencyResetForNewDefs(t *testing.T) {
+	type Config int
+	type Service int
+	var counter int
+	scope := New(
+		func() Config { return 1 },
+		func(s Scope) Service {
+			counter++
+			return Service(Get[Config](s))
+		},
+	)
+	if s := Get[Service](scope); s != 1 {
+		t.Fatalf("expected 1, got %d", s)
+	}
+
+	noNewDefs := scope.Fork()
+	if s := Get[Service](noNewDefs); s != 1 {
+		t.Fatalf("expected 1, got %d", s)
+	}
+	if counter != 1 {
+		t.Fatalf("provider re-evaluated without new definitions, got %d", counter)
+	}
+
+	child := scope.Fork(func() Config { return 3 })
+	if s := Get[Service](child); s != 3 {
+		t.Fatalf("expected 3, got %d", s)
+	}
+	if counter != 2 {
+		t.Fatalf("expected provider to run twice, got %d", counter)
+	}
+}
@@ -0,0 +1,133 @@
+package dscope
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+const TheoryOfSelectDefinitions = `
+dscope select definition theory:
+- Select[T] defines T by choosing one of several keyed alternative definitions
+  at resolution time. A selector function, whose parameters are ordinary
+  dependencies, returns the key of the alternative to use.
+- Only the chosen alternative is evaluated, so only its dependencies are
+  resolved and initialized.
+- The declared dependencies of T are the selector's parameters plus the
+  parameters of every alternative. Dependency analysis therefore covers all
+  alternatives: loops and missing dependencies are reported regardless of the
+  key, and a Fork redefining any of them, including the selector's inputs,
+  resets T.
+- A key without an alternative fails resolution with ErrBadDefinition.
+- Pointer alternatives are copied when Select is called, like pointer
+  definitions, so later changes to the pointed values are not observed.
+`
+
+// Select returns a definition of T that calls selector with dependencies from
+// the scope and provides T from the alternative registered under the returned
+// key. An alternative is a function returning T or a pointer to T.
+func Select[T any, K comparable](selector any, alternatives map[K]any) any {
+	t := reflect.TypeFor[T]()
+	keyType := reflect.TypeFor[K]()
+
+	selectorValue := reflect.ValueOf(selector)
+	selectorType := validateCallableValue(selectorValue)
+	if selectorType.NumOut() != 1 || selectorType.Out(0) != keyType {
+		panic(&BadArgumentError{
+			Reason: fmt.Sprintf("selector %v does not return %v", selectorType, keyType),
+		})
+	}
+	if len(alternatives) == 0 {
+		panic(&BadArgumentError{
+			Reason: fmt.Sprintf("no alternatives for %v", t),
+		})
+	}
+
+	var dependencies []reflect.Type
+	addDependencies := func(fnType reflect.Type) {
+		for i := range fnType.NumIn() {
+			in := fnType.In(i)
+			if !slices.Contains(dependencies, in) {
+				dependencies = append(dependencies, in)
+			}
+		}
+	}
+	addDependencies(selectorType)
+
+	// alternatives are copied, so later changes to the map or to pointed values
+	// do not affect the definition
+	funcs := make(map[K]any, len(alternatives))
+	positions := make(map[K]int, len(alternatives))
+	values := make(map[K]T)
+	for key, alternative := range alternatives {
+		if alternative == nil {
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("nil alternative %v for %v", key, t),
+			})
+		}
+		altType := reflect.TypeOf(alternative)
+		switch altType.Kind() {
+		case reflect.Func:
+			if reflect.ValueOf(alternative).IsNil() {
+				panic(&BadArgumentError{
+					Reason: fmt.Sprintf("nil alternative %v for %v", key, t),
+				})
+			}
+			position := -1
+			for i := range altType.NumOut() {
+				if altType.Out(i) == t {
+					position = i
+					break
+				}
+			}
+			if position < 0 {
+				panic(&BadArgumentError{
+					Reason: fmt.Sprintf("alternative %v of type %v does not provide %v", key, altType, t),
+				})
+			}
+			funcs[key] = alternative
+			positions[key] = position
+			addDependencies(altType)
+		case reflect.Pointer:
+			if altType.Elem() != t || reflect.ValueOf(alternative).IsNil() {
+				panic(&BadArgumentError{
+					Reason: fmt.Sprintf("alternative %v of type %v does not provide %v", key, altType, t),
+				})
+			}
+			values[key] = *alternative.(*T)
+		default:
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("alternative %v of type %v is not a valid definition", key, altType),
+			})
+		}
+	}
+	// map iteration order is random; the declared signature must not be
+	slices.SortFunc(dependencies, func(a, b reflect.Type) int {
+		return cmp.Compare(getTypeID(a), getTypeID(b))
+	})
+
+	return _DeclaredProvider{
+		DefType: reflect.FuncOf(dependencies, []reflect.Type{t}, false),
+		Func: func(scope Scope) (ret T) {
+			var key K
+			scope.CallValue(selectorValue).Extract(&key)
+			if value, ok := values[key]; ok {
+				return value
+			}
+			alternative, ok := funcs[key]
+			if !ok {
+				panic(errors.Join(
+					fmt.Errorf("no alternative %v for %v", key, t),
+					ErrBadDefinition,
+				))
+			}
+			value := scope.Call(alternative).Values[positions[key]]
+			if t.Kind() == reflect.Interface && value.IsNil() {
+				return
+			}
+			return value.Interface().(T)
+		},
+	}
+}
@@ -0,0 +1,209 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testSelectStorage interface {
+	Name() string
+}
+
+type testSelectS3 struct{}
+
+func (testSelectS3) Name() string { return "s3" }
+
+type testSelectFS struct{}
+
+func (testSelectFS) Name() string { return "fs" }
+
+type testSelectConfig struct {
+	Backend string
+}
+
+type testSelectS3Client int
+
+type testSelectFSRoot string
+
+func TestSelect(t *testing.T) {
+	var s3Inits, fsInits int
+	defs := []any{
+		Provide(testSelectConfig{Backend: "s3"}),
+		func() testSelectS3Client {
+			s3Inits++
+			return 1
+		},
+		func() testSelectFSRoot {
+			fsInits++
+			return "/"
+		},
+		Select[testSelectStorage](
+			func(cfg testSelectConfig) string {
+				return cfg.Backend
+			},
+			map[string]any{
+				"s3": func(testSelectS3Client) testSelectStorage {
+					return testSelectS3{}
+				},
+				"fs": func(testSelectFSRoot) testSelectStorage {
+					return testSelectFS{}
+				},
+			},
+		),
+	}
+	scope := New(defs...)
+	if name := Get[testSelectStorage](scope).Name(); name != "s3" {
+		t.Fatalf("got %s", name)
+	}
+	if s3Inits != 1 || fsInits != 0 {
+		t.Fatalf("only the chosen alternative should be initialized, got %d %d", s3Inits, fsInits)
+	}
+
+	// the declared dependencies cover every alternative
+	value, ok := scope.values.Load(getTypeID(reflect.TypeFor[testSelectStorage]()))
+	if !ok {
+		t.Fatal()
+	}
+	if n := len(value.typeInfo.Dependencies); n != 3 {
+		t.Fatalf("expected 3 dependencies, got %d", n)
+	}
+
+	// changing the selector input resets T
+	child := scope.Fork(Provide(testSelectConfig{Backend: "fs"}))
+	if name := Get[testSelectStorage](child).Name(); name != "fs" {
+		t.Fatalf("got %s", name)
+	}
+	if fsInits != 1 {
+		t.Fatalf("got %d", fsInits)
+	}
+
+	// same definitions share the cached forker
+	if New(defs...).forkFuncKey != scope.forkFuncKey {
+		t.Fatal("cache key should be deterministic")
+	}
+}
+
+func TestSelectPointerAlternative(t *testing.T) {
+	scope := New(
+		Provide(2),
+		Select[string](
+			func(i int) int {
+				return i
+			},
+			map[int]any{
+				1: Provide("one"),
+				2: Provide("two"),
+			},
+		),
+	)
+	if s := Get[string](scope); s != "two" {
+		t.Fatalf("got %s", s)
+	}
+
+	// pointer alternatives are copied when Select is called
+	s := "one"
+	alternatives := map[int]any{
+		1: &s,
+	}
+	def := Select[string](
+		func() int {
+			return 1
+		},
+		alternatives,
+	)
+	s = "changed"
+	alternatives[1] = Provide("replaced")
+	if got := Get[string](New(def)); got != "one" {
+		t.Fatalf("got %s", got)
+	}
+}
+
+func TestSelectLoop(t *testing.T) {
+	type A int
+	type B int
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		if err, ok := p.(error); !ok || !errors.Is(err, ErrDependencyLoop) {
+			t.Fatalf("got %v", p)
+		}
+	}()
+	New(
+		Provide(true),
+		func(a A) B {
+			return B(a)
+		},
+		Select[A](
+			func(b bool) bool {
+				return b
+			},
+			map[bool]any{
+				true: Provide(A(1)),
+				// loop through an alternative that is never chosen
+				false: func(b B) A {
+					return A(b)
+				},
+			},
+		),
+	)
+}
+
+func TestSelectMissingAlternative(t *testing.T) {
+	scope := New(
+		Provide(3),
+		Select[string](
+			func(i int) int {
+				return i
+			},
+			map[int]any{
+				1: Provide("one"),
+			},
+		),
+	)
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		if err, ok := p.(error); !ok || !errors.Is(err, ErrBadDefinition) {
+			t.Fatalf("got %v", p)
+		}
+	}()
+	Get[string](scope)
+}
+
+func TestSelectBadArgument(t *testing.T) {
+	for _, fn := range []func(){
+		func() {
+			Select[string](42, map[int]any{1: Provide("one")})
+		},
+		func() {
+			Select[string](func() string { return "" }, map[int]any{1: Provide("one")})
+		},
+		func() {
+			Select[string](func() int { return 1 }, map[int]any{})
+		},
+		func() {
+			Select[string](func() int { return 1 }, map[int]any{1: Provide(1)})
+		},
+		func() {
+			Select[string](func() int { return 1 }, map[int]any{1: func() int { return 1 }})
+		},
+	} {
+		func() {
+			defer func() {
+				p := recover()
+				if p == nil {
+					t.Fatal("should panic")
+				}
+				if err, ok := p.(error); !ok || !errors.Is(err, ErrBadArgument) {
+					t.Fatalf("got %v", p)
+				}
+			}()
+			fn()
+		}()
+	}
+}
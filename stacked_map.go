@@ -61,6 +61,7 @@ func (s *_StackedMap) Load(id _TypeID) (ret _Value, ok bool) {
 
 // refreshValue returns v with a fresh initializer, cached per reset layer.
 // Pointer initializers are returned as-is because they never need re-evaluation.
+// Reset-exempt initializers are kept unless a dependency is refreshed.
 func (s *_StackedMap) refreshValue(v _Value) _Value {
 	if v.initializer.DefIsPointer {
 		return v
@@ -71,33 +72,73 @@ func (s *_StackedMap) refreshValue(v _Value) _Value {
 			initializer: cached.(*_Initializer),
 		}
 	}
-	actual, _ := s.ResetCache.LoadOrStore(v.initializer.ID, v.initializer.reset())
+	fresh := v.initializer
+	if !v.typeInfo.ResetExempt || s.dependenciesRefreshed(v.typeInfo) {
+		fresh = v.initializer.reset()
+	}
+	actual, _ := s.ResetCache.LoadOrStore(v.initializer.ID, fresh)
 	return _Value{
 		typeInfo:    v.typeInfo,
 		initializer: actual.(*_Initializer),
 	}
 }
 
+// dependenciesRefreshed reports whether the reset layer refreshes any
+// dependency of info. Opaque dependencies may reach any refreshed value and
+// count as refreshed.
+func (s *_StackedMap) dependenciesRefreshed(info *_TypeInfo) bool {
+	for _, id := range info.Dependencies {
+		if isOpaqueDependency(id) {
+			return true
+		}
+		if isAlwaysProvided(id) {
+			continue
+		}
+		dep, ok := s.ResetBase.Load(id)
+		if !ok {
+			// lazily resolved, which reset scopes resolve again
+			return true
+		}
+		if s.refreshValue(dep).initializer != dep.initializer {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *_StackedMap) IterValues() iter.Seq[_Value] {
+	return func(yield func(_Value) bool) {
+		for v := range s.IterDepths() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// IterDepths yields the effective values of the stack with the depths of the
+// layers defining them, the top layer being 0. A lazy reset layer counts as a layer
+// above its base.
+func (s *_StackedMap) IterDepths() iter.Seq2[_Value, int] {
 	if s != nil && s.ResetBase != nil {
 		resetLayer := s
-		return func(yield func(_Value) bool) {
-			for v := range resetLayer.ResetBase.IterValues() {
-				if !yield(resetLayer.refreshValue(v)) {
+		return func(yield func(_Value, int) bool) {
+			for v, depth := range resetLayer.ResetBase.IterDepths() {
+				if !yield(resetLayer.refreshValue(v), depth+1) {
 					return
 				}
 			}
 		}
 	}
-	return func(yield func(_Value) bool) {
+	return func(yield func(_Value, int) bool) {
 		keys := make(map[_TypeID]struct{})
-		for s != nil {
+		for depth := 0; s != nil; depth++ {
 			for _, d := range s.Values {
 				if _, ok := keys[d.typeInfo.TypeID]; ok {
 					continue
 				}
 				keys[d.typeInfo.TypeID] = struct{}{}
-				if !yield(d) {
+				if !yield(d, depth) {
 					return
 				}
 			}
@@ -113,18 +154,7 @@ func (s *_StackedMap) IterValues() iter.Seq[_Value] {
 // sorted stack so that subsequent binary searches remain correct.
 func (s *_StackedMap) Append(values []_Value) *_StackedMap {
 	if s != nil && s.ResetBase != nil {
-		var flatValues []_Value
-		for v := range s.IterValues() {
-			flatValues = append(flatValues, v)
-		}
-		slices.SortFunc(flatValues, func(a, b _Value) int {
-			return cmp.Compare(a.typeInfo.TypeID, b.typeInfo.TypeID)
-		})
-		base := &_StackedMap{
-			Values: flatValues,
-			Height: 1,
-		}
-		return base.Append(values)
+		return s.flatten().Append(values)
 	}
 	var height int = 1
 	if s != nil {
@@ -137,6 +167,25 @@ func (s *_StackedMap) Append(values []_Value) *_StackedMap {
 	}
 }
 
+// maxStackHeight is the height above which stacks are flattened before new
+// layers are added.
+const maxStackHeight = 16
+
+// flatten returns a single-layer stack holding the effective values of s.
+func (s *_StackedMap) flatten() *_StackedMap {
+	var flatValues []_Value
+	for v := range s.IterValues() {
+		flatValues = append(flatValues, v)
+	}
+	slices.SortFunc(flatValues, func(a, b _Value) int {
+		return cmp.Compare(a.typeInfo.TypeID, b.typeInfo.TypeID)
+	})
+	return &_StackedMap{
+		Values: flatValues,
+		Height: 1,
+	}
+}
+
 // Len returns the total number of individual _Value entries across all layers.
 func (s *_StackedMap) Len() int {
 	if s == nil {
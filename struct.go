@@ -0,0 +1,150 @@
+package dscope
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+const TheoryOfStructDefinitions = `
+dscope struct definition theory:
+- Struct[T]() derives a provider for a struct or pointer-to-struct type T from
+  its fields, using the same field rules as InjectStruct: Inject[T] fields,
+  fields tagged dscope:"." or dscope:"inject", and embedded structs.
+- The derived provider is an ordinary function definition whose parameters are
+  the field types, so dependencies are declared statically and take part in
+  loop detection and reset analysis like any other provider.
+- Inject[T] fields stay lazy, as with InjectStruct: T is not a static
+  dependency and is resolved through the scope when the field is called. A
+  struct with Inject[T] fields depends on Scope instead, so it is
+  re-initialized when a fork redefines anything.
+- Embedded pointers are allocated before their fields are filled.
+- After the fields are filled, an Init() error method on the struct runs. An
+  Init error panics with ErrProviderFailed and, like any provider panic, is not
+  cached.
+`
+
+// _StructFieldPath locates a field to fill in a struct, through embedded
+// structs and pointers.
+type _StructFieldPath struct {
+	Embedded []reflect.StructField
+	Info     _StructFieldInfo
+}
+
+// reflect.Type -> any
+var structProviders sync.Map
+
+// Struct returns a definition providing T, a struct or pointer-to-struct type,
+// with fields filled by the InjectStruct rules.
+func Struct[T any]() any {
+	t := reflect.TypeFor[T]()
+	if v, ok := structProviders.Load(t); ok {
+		return v
+	}
+	v, _ := structProviders.LoadOrStore(t, makeStructProvider(t))
+	return v
+}
+
+func makeStructProvider(t reflect.Type) any {
+	structType := t
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		panic(&BadArgumentError{
+			Reason: fmt.Sprintf("%v is not a struct or pointer to struct", t),
+		})
+	}
+
+	var paths []_StructFieldPath
+	var collect func(t reflect.Type, embedded []reflect.StructField)
+	collect = func(t reflect.Type, embedded []reflect.StructField) {
+		if len(embedded) > 64 {
+			panic(&BadArgumentError{
+				Reason: "recursive struct injection depth limit exceeded",
+			})
+		}
+		for _, info := range injectStructFields(t) {
+			if info.IsEmbedded {
+				fieldType := info.Type
+				if fieldType.Kind() == reflect.Pointer {
+					fieldType = fieldType.Elem()
+				}
+				collect(fieldType, append(embedded[:len(embedded):len(embedded)], info.Field))
+				continue
+			}
+			paths = append(paths, _StructFieldPath{
+				Embedded: embedded,
+				Info:     info,
+			})
+		}
+	}
+	collect(structType, nil)
+
+	ins := make([]reflect.Type, 0, len(paths)+1)
+	hasInject := false
+	for _, path := range paths {
+		if path.Info.IsInject {
+			hasInject = true
+			continue
+		}
+		ins = append(ins, path.Info.Type)
+	}
+	if hasInject {
+		ins = append(ins, reflect.TypeFor[Scope]())
+	}
+	fnType := reflect.FuncOf(ins, []reflect.Type{t}, false)
+
+	return reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+		ptr := reflect.New(structType)
+		var scope Scope
+		if hasInject {
+			scope = args[len(args)-1].Interface().(Scope)
+		}
+		argIndex := 0
+		for _, path := range paths {
+			value := ptr.Elem()
+			for _, field := range path.Embedded {
+				value = value.FieldByIndex(field.Index)
+				if value.Kind() == reflect.Pointer {
+					if value.IsNil() {
+						value.Set(reflect.New(value.Type().Elem()))
+					}
+					value = value.Elem()
+				}
+			}
+			target := value.FieldByIndex(path.Info.Field.Index)
+			if path.Info.IsInject {
+				typ := path.Info.Type
+				target.Set(reflect.MakeFunc(
+					path.Info.Field.Type,
+					func(_ []reflect.Value) []reflect.Value {
+						value, ok := scope.Get(typ)
+						if !ok {
+							throwErrDependencyNotFound(scope, typ)
+						}
+						return []reflect.Value{value}
+					},
+				))
+			} else {
+				target.Set(args[argIndex])
+				argIndex++
+			}
+		}
+
+		if initializer, ok := ptr.Interface().(interface{ Init() error }); ok {
+			if err := initializer.Init(); err != nil {
+				panic(errors.Join(
+					fmt.Errorf("init %v failed: %w", t, err),
+					ErrProviderFailed,
+				))
+			}
+		}
+
+		if t.Kind() == reflect.Pointer {
+			return []reflect.Value{ptr}
+		}
+		return []reflect.Value{ptr.Elem()}
+	}).Interface()
+}
@@ -0,0 +1,196 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type TestStructEmbeddedValue struct {
+	F float64 `dscope:"."`
+}
+
+type TestStructEmbeddedPointer struct {
+	S string `dscope:"."`
+}
+
+type testStructService struct {
+	TestStructEmbeddedValue
+	*TestStructEmbeddedPointer
+	I      int `dscope:"."`
+	Lazy   Inject[int8]
+	Ignore int16
+	inited bool
+}
+
+func (s *testStructService) Init() error {
+	if s.I < 0 {
+		return errors.New("negative")
+	}
+	s.inited = true
+	return nil
+}
+
+func TestStruct(t *testing.T) {
+	scope := New(
+		Provide(1.5),
+		Provide("foo"),
+		func() int { return 42 },
+		func() int8 { return 8 },
+		Struct[*testStructService](),
+	)
+	s := Get[*testStructService](scope)
+	if s.F != 1.5 {
+		t.Fatalf("got %v", s.F)
+	}
+	if s.S != "foo" {
+		t.Fatalf("got %v", s.S)
+	}
+	if s.I != 42 {
+		t.Fatalf("got %v", s.I)
+	}
+	if s.Lazy() != 8 {
+		t.Fatalf("got %v", s.Lazy())
+	}
+	if s.Ignore != 0 {
+		t.Fatal("untagged field should not be filled")
+	}
+	if !s.inited {
+		t.Fatal("Init not called")
+	}
+	if Get[*testStructService](scope) != s {
+		t.Fatal("struct value should be cached")
+	}
+}
+
+func TestStructValue(t *testing.T) {
+	type Service struct {
+		I int `dscope:"."`
+	}
+	scope := New(
+		func() int { return 42 },
+		Struct[Service](),
+	)
+	if s := Get[Service](scope); s.I != 42 {
+		t.Fatalf("got %v", s.I)
+	}
+}
+
+func TestStructStaticDependencies(t *testing.T) {
+	type Service struct {
+		I int `dscope:"."`
+	}
+	var calls int
+	scope := New(
+		func() int { return 42 },
+		func() string {
+			calls++
+			return "foo"
+		},
+		Struct[*Service](),
+	)
+	value, ok := scope.values.Load(getTypeID(reflect.TypeFor[*Service]()))
+	if !ok {
+		t.Fatal()
+	}
+	deps := value.typeInfo.Dependencies
+	if len(deps) != 1 || deps[0] != getTypeID(reflect.TypeFor[int]()) {
+		t.Fatalf("got %v", deps)
+	}
+
+	Get[*Service](scope)
+	// overriding an unrelated type must not rebuild the struct
+	child := scope.Fork(func() string { return "bar" })
+	if Get[*Service](child) != Get[*Service](scope) {
+		t.Fatal("struct should not be rebuilt")
+	}
+	// overriding a field type rebuilds the struct
+	child = scope.Fork(func() int { return 1 })
+	if s := Get[*Service](child); s.I != 1 {
+		t.Fatalf("got %v", s.I)
+	}
+}
+
+func TestStructDependencyNotFound(t *testing.T) {
+	type Service struct {
+		I int `dscope:"."`
+	}
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		if err, ok := p.(error); !ok || !errors.Is(err, ErrDependencyNotFound) {
+			t.Fatalf("got %v", p)
+		}
+	}()
+	New(Struct[*Service]())
+}
+
+func TestStructInitError(t *testing.T) {
+	scope := New(
+		Provide(1.5),
+		Provide("foo"),
+		func() int { return -1 },
+		func() int8 { return 8 },
+		Struct[*testStructService](),
+	)
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		if err, ok := p.(error); !ok || !errors.Is(err, ErrProviderFailed) {
+			t.Fatalf("got %v", p)
+		}
+	}()
+	Get[*testStructService](scope)
+}
+
+func TestStructBadType(t *testing.T) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		if err, ok := p.(error); !ok || !errors.Is(err, ErrBadArgument) {
+			t.Fatalf("got %v", p)
+		}
+	}()
+	Struct[int]()
+}
+
+func TestStructLazyInject(t *testing.T) {
+	type Dep int
+	type Service struct {
+		Dep Inject[Dep]
+	}
+	var calls int
+	scope := New(
+		func() Dep {
+			calls++
+			return 42
+		},
+		Struct[*Service](),
+	)
+	s := Get[*Service](scope)
+	if calls != 0 {
+		t.Fatal("Inject field should not be resolved eagerly")
+	}
+	if s.Dep() != 42 || calls != 1 {
+		t.Fatalf("got %v %v", s.Dep(), calls)
+	}
+
+	// Inject fields are not static dependencies
+	type Missing int
+	type Lazy struct {
+		M Inject[Missing]
+	}
+	scope = New(Struct[*Lazy]())
+	l := Get[*Lazy](scope)
+	if err := recoverError(t, func() {
+		l.M()
+	}); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package dscope
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+const TheoryOfSuggestions = `
+dscope suggestion theory:
+- Dependency-not-found errors carry suggestions for the common mistakes
+  behind them, ranked by kind and then by type name:
+  a pointer or element type of the wanted type is defined; a defined type
+  implements the wanted interface; a type with the same name from another
+  package is defined; the wanted type is private to a module of the scope.
+- Suggestions are computed only when the error is reported, by scanning the
+  types of the scope. They depend on the scope only.
+- A type provided only in a sibling Fork is not suggested. Scopes do not know
+  their siblings, and finding them would mean scanning every scope ever
+  forked, which the forker cache does not index by parent. This case is out
+  of scope until scopes track the types defined by their parent's children.
+`
+
+// SuggestionKind classifies a suggestion for a missing type.
+type SuggestionKind int
+
+const (
+	// SuggestPointer suggests the pointer or element type of the wanted type.
+	SuggestPointer SuggestionKind = iota + 1
+	// SuggestImplementation suggests a type implementing the wanted interface.
+	SuggestImplementation
+	// SuggestSameName suggests a type with the same name from another package.
+	SuggestSameName
+	// SuggestPrivate reports that the wanted type is private to a module.
+	SuggestPrivate
+)
+
+// Suggestion is a type that may have been meant instead of a missing type.
+type Suggestion struct {
+	Kind SuggestionKind
+	Type reflect.Type
+	// Module is the module the type is private to, for SuggestPrivate
+	Module reflect.Type
+}
+
+func (s Suggestion) String() string {
+	switch s.Kind {
+	case SuggestPointer:
+		return fmt.Sprintf("%v is defined", s.Type)
+	case SuggestImplementation:
+		return fmt.Sprintf("%v is defined and implements the interface", s.Type)
+	case SuggestSameName:
+		return fmt.Sprintf("%v from package %s is defined", s.Type, s.Type.PkgPath())
+	case SuggestPrivate:
+		return fmt.Sprintf("%v is private to module %v", s.Type, s.Module)
+	}
+	return s.Type.String()
+}
+
+// suggest returns suggestions for t, which has no definition in scope.
+func suggest(scope Scope, t reflect.Type) (ret []Suggestion) {
+	if t == nil {
+		return nil
+	}
+	for candidate := range scope.AllTypes() {
+		switch {
+		case candidate == reflect.PointerTo(t),
+			t.Kind() == reflect.Pointer && candidate == t.Elem():
+			ret = append(ret, Suggestion{
+				Kind: SuggestPointer,
+				Type: candidate,
+			})
+		case t.Kind() == reflect.Interface && candidate.Implements(t):
+			ret = append(ret, Suggestion{
+				Kind: SuggestImplementation,
+				Type: candidate,
+			})
+		case t.Name() != "" && candidate.Name() == t.Name() && candidate.PkgPath() != t.PkgPath():
+			ret = append(ret, Suggestion{
+				Kind: SuggestSameName,
+				Type: candidate,
+			})
+		}
+	}
+
+	for value := range scope.values.IterValues() {
+		if typeIDToType(value.typeInfo.TypeID) != t {
+			continue
+		}
+		if module, ok := privateTypeModule(value.typeInfo.TypeID); ok {
+			ret = append(ret, Suggestion{
+				Kind:   SuggestPrivate,
+				Type:   t,
+				Module: module,
+			})
+		}
+	}
+
+	slices.SortStableFunc(ret, func(a, b Suggestion) int {
+		return cmp.Or(
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Type.String(), b.Type.String()),
+		)
+	})
+	return
+}
@@ -0,0 +1,99 @@
+package dscope
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func suggestionsOf(t *testing.T, fn func()) []Suggestion {
+	t.Helper()
+	err := recoverError(t, fn)
+	var notFound *DependencyNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("got %v", err)
+	}
+	return notFound.Suggestions
+}
+
+func TestSuggestPointer(t *testing.T) {
+	type Foo struct{}
+	scope := New(func() *Foo { return new(Foo) })
+	suggestions := suggestionsOf(t, func() {
+		Get[Foo](scope)
+	})
+	if len(suggestions) != 1 || suggestions[0].Kind != SuggestPointer || suggestions[0].Type.String() != "*dscope.Foo" {
+		t.Fatalf("got %v", suggestions)
+	}
+
+	// fork time
+	err := recoverError(t, func() {
+		scope.Fork(func(Foo) int { return 1 })
+	})
+	if !strings.Contains(err.Error(), "did you mean: *dscope.Foo is defined") {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestSuggestImplementation(t *testing.T) {
+	scope := New(func() *bytes.Buffer { return new(bytes.Buffer) })
+	suggestions := suggestionsOf(t, func() {
+		Get[io.Writer](scope)
+	})
+	if len(suggestions) != 1 || suggestions[0].Kind != SuggestImplementation {
+		t.Fatalf("got %v", suggestions)
+	}
+}
+
+func TestSuggestSameName(t *testing.T) {
+	type Buffer int
+	scope := New(func() bytes.Buffer { return bytes.Buffer{} })
+	suggestions := suggestionsOf(t, func() {
+		Get[Buffer](scope)
+	})
+	if len(suggestions) != 1 || suggestions[0].Kind != SuggestSameName {
+		t.Fatalf("got %v", suggestions)
+	}
+}
+
+func TestSuggestPrivate(t *testing.T) {
+	scope := New(new(testPrivateModule))
+	suggestions := suggestionsOf(t, func() {
+		Get[testPrivateHelper](scope)
+	})
+	if len(suggestions) != 1 || suggestions[0].Kind != SuggestPrivate {
+		t.Fatalf("got %v", suggestions)
+	}
+}
+
+type testSuggestSibling int
+
+func TestSuggestNoOtherScope(t *testing.T) {
+	// types defined in sibling scopes are not suggested
+	base := New(func() int8 { return 1 })
+	base.Fork(func() testSuggestSibling { return 1 })
+	suggestions := suggestionsOf(t, func() {
+		Get[testSuggestSibling](base.Fork(func() int16 { return 1 }))
+	})
+	if len(suggestions) != 0 {
+		t.Fatalf("got %v", suggestions)
+	}
+}
+
+func TestSuggestRanking(t *testing.T) {
+	type Foo struct{}
+	scope := New(
+		func() *Foo { return nil },
+		func() *bytes.Buffer { return nil },
+	)
+	suggestions := suggestionsOf(t, func() {
+		Get[interface{}](scope)
+	})
+	for i := 1; i < len(suggestions); i++ {
+		if suggestions[i-1].Kind > suggestions[i].Kind {
+			t.Fatalf("not ranked: %v", suggestions)
+		}
+	}
+}
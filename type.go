@@ -57,6 +57,21 @@ func isAlwaysProvided(id _TypeID) bool {
 		return true
 	case resetTypeID:
 		return true
+	case scopeTypeID:
+		return true
+	case callTypeID:
+		return true
+	}
+	return false
+}
+
+// isOpaqueDependency reports whether a provider depending on id may resolve
+// arbitrary types from the scope at runtime, so that its real dependencies
+// cannot be determined statically.
+func isOpaqueDependency(id _TypeID) bool {
+	switch id {
+	case injectStructTypeID, forkTypeID, resetTypeID, scopeTypeID, callTypeID:
+		return true
 	}
 	return false
 }
@@ -0,0 +1,500 @@
+package dscope
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const TheoryOfValidation = `
+dscope validation theory:
+- Fork panics on the first problem of its definitions. Validate analyzes the
+  definitions of a scope together with the definitions a Fork would add, and
+  reports every problem at once, without creating a scope or running the
+  providers of any definition.
+- Missing dependencies, dependency loops and duplicate definitions are reported
+  as the typed errors Fork panics with. Other problems, such as invalid
+  definitions, strict redefinitions, private dependencies and module contract
+  violations, are reported as plain errors. Of duplicate definitions, the first
+  one is analyzed further.
+- Predicates of When and configuration fields of modules are not evaluated.
+  Their parameter and field types are checked as dependencies instead, and
+  directives of configured modules see zero-valued fields.
+- The definitions of every When are analyzed as if all predicates held, but
+  duplicates between them are not reported, since predicates may exclude each
+  other. Other definitions are prepared as in Fork; a definition that cannot
+  be prepared ends the analysis with that single error.
+- Every dependency on InjectStruct, Fork, Reset, Scope or Call is reported as
+  an opaque dependency, since what a provider resolves through it cannot be
+  checked statically. Opaque dependencies are not errors.
+- Call targets and InjectStruct targets validate the entry points of an
+  application along with its definitions. Like Get, they accept types the
+  resolvers of the scope can provide.
+`
+
+// ValidationReport lists the problems found by Scope.Validate.
+type ValidationReport struct {
+	// NotFound lists dependencies without definition
+	NotFound []*DependencyNotFoundError
+	// Loops lists dependency loops
+	Loops []*DependencyLoopError
+	// Duplicates lists types with more than one definition
+	Duplicates []*DuplicateDefinitionError
+	// Opaque lists dependencies whose resolutions cannot be checked statically
+	Opaque []OpaqueDependency
+	// Errors lists other problems
+	Errors []error
+}
+
+// OpaqueDependency is a dependency on InjectStruct, Fork, Reset, Scope or
+// Call.
+type OpaqueDependency struct {
+	// Type is the opaque type
+	Type reflect.Type
+	// RequiredBy is the type of the definition or target depending on Type
+	RequiredBy reflect.Type
+	// Source describes where the definition or target came from
+	Source string
+}
+
+func (o OpaqueDependency) String() string {
+	return fmt.Sprintf("%v depends on %v, by %s", o.RequiredBy, o.Type, o.Source)
+}
+
+// Err returns the problems in the report joined, or nil if there are none.
+// Opaque dependencies are not problems.
+func (r *ValidationReport) Err() error {
+	var errs []error
+	for _, err := range r.NotFound {
+		errs = append(errs, err)
+	}
+	for _, err := range r.Loops {
+		errs = append(errs, err)
+	}
+	for _, err := range r.Duplicates {
+		errs = append(errs, err)
+	}
+	errs = append(errs, r.Errors...)
+	return errors.Join(errs...)
+}
+
+// ValidationTarget is an entry point validated by Scope.Validate.
+type ValidationTarget struct {
+	// Type is the function type of a Call target or the struct type of an
+	// InjectStruct target
+	Type reflect.Type
+	// Source describes where the target came from
+	Source string
+}
+
+// CallTarget returns a target validating that the scope can call fn, which is
+// a function or a function type.
+func CallTarget(fn any) ValidationTarget {
+	t, ok := fn.(reflect.Type)
+	if !ok {
+		if fn == nil {
+			panic(&BadArgumentError{
+				Reason: "nil call target",
+			})
+		}
+		t = reflect.TypeOf(fn)
+	}
+	if t.Kind() != reflect.Func {
+		panic(&BadArgumentError{
+			Reason: fmt.Sprintf("call target %v is not a function", t),
+		})
+	}
+	source := fmt.Sprintf("call target passed at %s", callerLocation())
+	if location := funcLocation(reflect.ValueOf(fn)); location != "" {
+		source = "call target " + location
+	}
+	return ValidationTarget{
+		Type:   t,
+		Source: source,
+	}
+}
+
+// InjectTarget returns a target validating that the scope can inject target,
+// which is a pointer to struct, a struct type or a pointer-to-struct type.
+func InjectTarget(target any) ValidationTarget {
+	t, ok := target.(reflect.Type)
+	if !ok {
+		if target == nil {
+			panic(&BadArgumentError{
+				Reason: "nil inject target",
+			})
+		}
+		t = reflect.TypeOf(target)
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(&BadArgumentError{
+			Reason: fmt.Sprintf("inject target %v is not a struct or pointer to struct", t),
+		})
+	}
+	return ValidationTarget{
+		Type:   t,
+		Source: fmt.Sprintf("inject target passed at %s", callerLocation()),
+	}
+}
+
+// Validate reports every problem of the definitions of the scope, of defs as
+// Fork would add them, and of the ValidationTarget values in defs.
+func (scope Scope) Validate(defs ...any) *ValidationReport {
+	report := new(ValidationReport)
+
+	var targets []ValidationTarget
+	defs = slices.DeleteFunc(slices.Clone(defs), func(def any) bool {
+		target, ok := def.(ValidationTarget)
+		if ok {
+			targets = append(targets, target)
+		}
+		return ok
+	})
+
+	v := &_Validation{
+		report:   report,
+		defOf:    make(map[_TypeID]int),
+		reported: make(map[_ValidationKey]struct{}),
+	}
+	if err := catchError(func() {
+		v.defs, v.resolvers, _ = prepareDefinitions(scope, defs, v)
+	}); err != nil {
+		report.Errors = append(report.Errors, err)
+		return report
+	}
+
+	v.addDefinitions(scope, v.defs)
+	v.checkGraph()
+	for _, requirement := range v.requirements {
+		v.checkDependency(requirement.RequiredBy, nil, requirement.Source, getTypeID(requirement.Type), nil)
+	}
+	for _, target := range targets {
+		v.checkTarget(target)
+	}
+	v.sort()
+	return report
+}
+
+// catchError calls fn, returning the error it panics with.
+func catchError(fn func()) (err error) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		}
+		var ok bool
+		err, ok = p.(error)
+		if !ok {
+			panic(p)
+		}
+	}()
+	fn()
+	return
+}
+
+// _Validation is the state of a Scope.Validate analysis.
+type _Validation struct {
+	report    *ValidationReport
+	resolvers *_Resolvers
+	values    *_StackedMap
+	defs      []any
+	// defOf maps the types provided by defs to the index of their definition
+	defOf map[_TypeID]int
+	// reported records reported dependencies of definitions
+	reported map[_ValidationKey]struct{}
+	// requirements lists the dependencies of predicates and module
+	// configuration fields
+	requirements []_Requirement
+}
+
+// _Requirement is a dependency of a predicate or a module configuration field,
+// which Validate checks instead of resolving.
+type _Requirement struct {
+	RequiredBy reflect.Type
+	Source     string
+	Type       reflect.Type
+}
+
+// require records the dependency of requiredBy on t.
+func (v *_Validation) require(requiredBy reflect.Type, source string, t reflect.Type) {
+	v.requirements = append(v.requirements, _Requirement{
+		RequiredBy: requiredBy,
+		Source:     source,
+		Type:       t,
+	})
+}
+
+// requirePredicate records the dependencies of a predicate of When.
+func (v *_Validation) requirePredicate(predicate reflect.Value) {
+	predicateType := predicate.Type()
+	source := fmt.Sprintf("predicate %v", predicateType)
+	if location := funcLocation(predicate); location != "" {
+		source = "predicate " + location
+	}
+	for i := range predicateType.NumIn() {
+		v.require(predicateType, source, predicateType.In(i))
+	}
+}
+
+// _ValidationKey identifies the dependency of a definition or target.
+type _ValidationKey struct {
+	RequiredBy reflect.Type
+	Module     *_ModuleInfo
+	Dependency _TypeID
+}
+
+// addDefinitions builds the values of the scope forked with defs.
+func (v *_Validation) addDefinitions(scope Scope, defs []any) {
+	skipped := skippedDefaults(scope, defs)
+	duplicates := make(map[_TypeID]*DuplicateDefinitionError)
+	var newValues []_Value
+	for i, def := range defs {
+		if skipped != nil && skipped[i] {
+			continue
+		}
+		defType := definitionType(def)
+		if err := definitionError(defType, reflect.ValueOf(definitionFunc(def))); err != nil {
+			v.report.Errors = append(v.report.Errors, err)
+			continue
+		}
+		var dependencies []_TypeID
+		for _, t := range definitionInputTypes(def) {
+			dependencies = append(dependencies, definitionTypeID(def, t))
+		}
+		for position, t := range definitionOutputTypes(def) {
+			id := definitionTypeID(def, t)
+			if prev, ok := v.defOf[id]; ok {
+				if definitionFlags(defs[prev])&definitionFlags(def)&defConditional != 0 {
+					// predicates may exclude each other
+					continue
+				}
+				duplicate, ok := duplicates[id]
+				if !ok {
+					duplicate = &DuplicateDefinitionError{
+						Type: t,
+						Definitions: []string{
+							definitionSource(defs[prev], true),
+						},
+					}
+					duplicates[id] = duplicate
+					v.report.Duplicates = append(v.report.Duplicates, duplicate)
+				}
+				duplicate.Definitions = append(duplicate.Definitions, definitionSource(def, true))
+				continue
+			}
+			if inherited, ok := scope.values.Load(id); ok && scope.strict && definitionFlags(def)&defOverride == 0 {
+				v.report.Errors = append(v.report.Errors, redefinitionError(def, t, inherited))
+			}
+			v.defOf[id] = i
+			newValues = append(newValues, _Value{
+				typeInfo: &_TypeInfo{
+					TypeID:       id,
+					DefType:      defType,
+					Position:     position,
+					Dependencies: dependencies,
+					Module:       definitionModule(def),
+				},
+			})
+		}
+	}
+	slices.SortFunc(newValues, func(a, b _Value) int {
+		return cmp.Compare(a.typeInfo.TypeID, b.typeInfo.TypeID)
+	})
+	v.values = scope.values.Append(newValues)
+}
+
+// source describes where the definition of value came from.
+func (v *_Validation) source(value _Value) string {
+	if i, ok := v.defOf[value.typeInfo.TypeID]; ok {
+		return definitionSource(v.defs[i], true)
+	}
+	return valueSource(value)
+}
+
+// defined reports whether id has a definition or can be resolved.
+func (v *_Validation) defined(id _TypeID) bool {
+	if _, ok := v.values.Load(id); ok {
+		return true
+	}
+	if v.resolvers == nil {
+		return false
+	}
+	var ok bool
+	if err := catchError(func() {
+		_, ok = v.resolvers.resolve(typeIDToType(id))
+	}); err != nil {
+		v.report.Errors = append(v.report.Errors, err)
+		return true
+	}
+	return ok
+}
+
+// checkDependency reports problems of the dependency of requiredBy on id. It
+// reports whether the dependency should be followed.
+func (v *_Validation) checkDependency(
+	requiredBy reflect.Type,
+	module *_ModuleInfo,
+	source string,
+	id _TypeID,
+	path []_TypeID,
+) bool {
+	key := _ValidationKey{
+		RequiredBy: requiredBy,
+		Module:     module,
+		Dependency: id,
+	}
+	if isAlwaysProvided(id) {
+		if !isOpaqueDependency(id) {
+			return false
+		}
+		if _, ok := v.reported[key]; !ok {
+			v.reported[key] = struct{}{}
+			v.report.Opaque = append(v.report.Opaque, OpaqueDependency{
+				Type:       typeIDToType(id),
+				RequiredBy: requiredBy,
+				Source:     source,
+			})
+		}
+		return false
+	}
+	if v.defined(id) {
+		return true
+	}
+	if _, ok := v.reported[key]; ok {
+		return false
+	}
+	v.reported[key] = struct{}{}
+	if err := privateDependencyError(v.values, requiredBy, id); err != nil {
+		v.report.Errors = append(v.report.Errors, err)
+		return false
+	}
+	notFoundErr := &DependencyNotFoundError{
+		Type:       typeIDToType(id),
+		RequiredBy: requiredBy,
+		Source:     source,
+		Suggestions: suggest(Scope{
+			values: v.values,
+		}, typeIDToType(id)),
+	}
+	for _, pathID := range path {
+		notFoundErr.Path = append(notFoundErr.Path, typeIDToType(pathID))
+	}
+	v.report.NotFound = append(v.report.NotFound, notFoundErr)
+	return false
+}
+
+// checkGraph reports missing and opaque dependencies and loops of all values.
+func (v *_Validation) checkGraph() {
+	colors := make(map[_TypeID]int) // 0=White, 1=Gray, 2=Black
+	loops := make(map[string]struct{})
+
+	var visit func(value _Value, path []_TypeID)
+	visit = func(value _Value, path []_TypeID) {
+		id := value.typeInfo.TypeID
+		switch colors[id] {
+		case 1:
+			v.addLoop(loops, path[slices.Index(path, id):])
+			return
+		case 2:
+			return
+		}
+		colors[id] = 1
+		path = append(path, id)
+		for _, depID := range value.typeInfo.Dependencies {
+			if !v.checkDependency(value.typeInfo.DefType, value.typeInfo.Module, v.source(value), depID, path) {
+				continue
+			}
+			if depValue, ok := v.values.Load(depID); ok {
+				visit(depValue, path)
+			}
+		}
+		colors[id] = 2
+	}
+	for value := range v.values.IterValues() {
+		visit(value, nil)
+	}
+
+	if err := checkModuleContracts(v.values); err != nil {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			v.report.Errors = append(v.report.Errors, joined.Unwrap()...)
+		} else {
+			v.report.Errors = append(v.report.Errors, err)
+		}
+	}
+}
+
+// addLoop reports cycle if it is not reported yet.
+func (v *_Validation) addLoop(loops map[string]struct{}, cycle []_TypeID) {
+	// rotate to start with the smallest type ID
+	start := slices.Index(cycle, slices.Min(cycle))
+	cycle = append(slices.Clone(cycle[start:]), cycle[:start]...)
+	key := fmt.Sprint(cycle)
+	if _, ok := loops[key]; ok {
+		return
+	}
+	loops[key] = struct{}{}
+	loopErr := new(DependencyLoopError)
+	for _, id := range cycle {
+		value, _ := v.values.Load(id)
+		loopErr.Cycle = append(loopErr.Cycle, typeIDToType(id))
+		loopErr.Sources = append(loopErr.Sources, v.source(value))
+	}
+	v.report.Loops = append(v.report.Loops, loopErr)
+}
+
+// checkTarget reports missing and opaque dependencies of target.
+func (v *_Validation) checkTarget(target ValidationTarget) {
+	if target.Type.Kind() == reflect.Func {
+		for i := range target.Type.NumIn() {
+			v.checkDependency(target.Type, nil, target.Source, getTypeID(target.Type.In(i)), nil)
+		}
+		return
+	}
+	v.checkStruct(target, target.Type, make(map[reflect.Type]bool))
+}
+
+// checkStruct reports missing and opaque dependencies of the fields of struct
+// type t injected for target.
+func (v *_Validation) checkStruct(target ValidationTarget, t reflect.Type, visited map[reflect.Type]bool) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+	for _, info := range injectStructFields(t) {
+		if info.IsEmbedded {
+			embedded := info.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			v.checkStruct(target, embedded, visited)
+			continue
+		}
+		source := fmt.Sprintf("field %s of %v, %s", info.Field.Name, t, target.Source)
+		v.checkDependency(target.Type, nil, source, getTypeID(info.Type), nil)
+	}
+}
+
+// sort orders the problems in the report by their messages.
+func (v *_Validation) sort() {
+	byMessage := func(a, b error) int {
+		return strings.Compare(a.Error(), b.Error())
+	}
+	slices.SortStableFunc(v.report.NotFound, func(a, b *DependencyNotFoundError) int {
+		return byMessage(a, b)
+	})
+	slices.SortStableFunc(v.report.Loops, func(a, b *DependencyLoopError) int {
+		return byMessage(a, b)
+	})
+	slices.SortStableFunc(v.report.Duplicates, func(a, b *DuplicateDefinitionError) int {
+		return byMessage(a, b)
+	})
+	slices.SortStableFunc(v.report.Opaque, func(a, b OpaqueDependency) int {
+		return strings.Compare(a.String(), b.String())
+	})
+}
@@ -0,0 +1,369 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateReportsAll(t *testing.T) {
+	type Missing1 int
+	type Missing2 int
+	type A int
+	type B int
+	type C int
+	type Dup int
+	report := New().Validate(
+		func(Missing1) A {
+			panic("should not run")
+		},
+		func(Missing2, Missing1) C {
+			panic("should not run")
+		},
+		func(c B) C {
+			panic("should not run")
+		},
+		func(c C) B {
+			panic("should not run")
+		},
+		func() Dup {
+			panic("should not run")
+		},
+		func() Dup {
+			panic("should not run")
+		},
+		new(Dup),
+	)
+
+	// reported for each definition requiring it
+	if len(report.NotFound) != 3 {
+		t.Fatalf("got %v", report.NotFound)
+	}
+	var notFound []reflect.Type
+	for _, err := range report.NotFound {
+		notFound = append(notFound, err.Type)
+	}
+	if !slices.Contains(notFound, reflect.TypeFor[Missing1]()) ||
+		!slices.Contains(notFound, reflect.TypeFor[Missing2]()) {
+		t.Fatalf("got %v", notFound)
+	}
+
+	// the first definition of C is analyzed
+	if len(report.Loops) != 0 {
+		t.Fatalf("got %v", report.Loops)
+	}
+
+	if len(report.Duplicates) != 2 {
+		t.Fatalf("got %v", report.Duplicates)
+	}
+	for _, duplicate := range report.Duplicates {
+		switch duplicate.Type {
+		case reflect.TypeFor[C]():
+			if len(duplicate.Definitions) != 2 {
+				t.Fatalf("got %v", duplicate.Definitions)
+			}
+		case reflect.TypeFor[Dup]():
+			if len(duplicate.Definitions) != 3 {
+				t.Fatalf("got %v", duplicate.Definitions)
+			}
+		default:
+			t.Fatalf("got %v", duplicate.Type)
+		}
+	}
+
+	err := report.Err()
+	if !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("got %v", err)
+	}
+	if !errors.Is(err, ErrBadDefinition) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestValidateLoops(t *testing.T) {
+	type A int
+	type B int
+	type C int
+	type D int
+	report := New().Validate(
+		func(B) A {
+			return 0
+		},
+		func(A) B {
+			return 0
+		},
+		func(D, A) C {
+			return 0
+		},
+		func(C) D {
+			return 0
+		},
+	)
+	if len(report.Loops) != 2 {
+		t.Fatalf("got %v", report.Loops)
+	}
+	for _, loop := range report.Loops {
+		if len(loop.Cycle) != 2 || len(loop.Sources) != 2 {
+			t.Fatalf("got %v", loop)
+		}
+	}
+	if !errors.Is(report.Err(), ErrDependencyLoop) {
+		t.Fatalf("got %v", report.Err())
+	}
+}
+
+func TestValidateOK(t *testing.T) {
+	type A int
+	type B int
+	scope := New(func() A {
+		panic("should not run")
+	})
+	report := scope.Validate(
+		func(a A, scope Scope) B {
+			panic("should not run")
+		},
+	)
+	if err := report.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Opaque) != 1 {
+		t.Fatalf("got %v", report.Opaque)
+	}
+	opaque := report.Opaque[0]
+	if opaque.Type != reflect.TypeFor[Scope]() ||
+		opaque.RequiredBy != reflect.TypeFor[func(A, Scope) B]() {
+		t.Fatalf("got %v", opaque)
+	}
+	if !strings.Contains(opaque.Source, "validate_test.go") {
+		t.Fatalf("got %v", opaque.Source)
+	}
+}
+
+func TestValidateInheritedDefinitions(t *testing.T) {
+	type A int
+	type B int
+	scope := New(
+		func() A {
+			return 1
+		},
+		func(a A) B {
+			return B(a)
+		},
+	)
+	report := scope.Validate(
+		func(b B) A {
+			return A(b)
+		},
+	)
+	if len(report.Loops) != 1 {
+		t.Fatalf("got %v", report.Loops)
+	}
+	if !slices.Equal(report.Loops[0].Cycle, []reflect.Type{
+		reflect.TypeFor[A](),
+		reflect.TypeFor[B](),
+	}) {
+		t.Fatalf("got %v", report.Loops[0].Cycle)
+	}
+}
+
+func TestValidateTargets(t *testing.T) {
+	type A int
+	type Missing1 int
+	type Missing2 int
+	type Missing3 int
+	type Embedded struct {
+		M Missing3 `dscope:"."`
+	}
+	type Target struct {
+		Embedded
+		A  A         `dscope:"."`
+		M  Missing2  `dscope:"."`
+		I  Inject[A] `dscope:"."`
+		I2 Inject[Missing2]
+	}
+	scope := New(func() A {
+		return 1
+	})
+	report := scope.Validate(
+		CallTarget(func(A, Missing1, Fork) {}),
+		InjectTarget(reflect.TypeFor[*Target]()),
+	)
+	var types []reflect.Type
+	for _, err := range report.NotFound {
+		types = append(types, err.Type)
+	}
+	if len(types) != 3 ||
+		!slices.Contains(types, reflect.TypeFor[Missing1]()) ||
+		!slices.Contains(types, reflect.TypeFor[Missing2]()) ||
+		!slices.Contains(types, reflect.TypeFor[Missing3]()) {
+		t.Fatalf("got %v", types)
+	}
+	for _, err := range report.NotFound {
+		if !strings.Contains(err.Source, "target") {
+			t.Fatalf("got %v", err.Source)
+		}
+	}
+	if len(report.Opaque) != 1 || report.Opaque[0].Type != reflect.TypeFor[Fork]() {
+		t.Fatalf("got %v", report.Opaque)
+	}
+}
+
+func TestValidateTargetsResolvers(t *testing.T) {
+	type A int
+	scope := New(Resolver(func(t reflect.Type) (any, bool) {
+		if t == reflect.TypeFor[A]() {
+			return func() A {
+				return 1
+			}, true
+		}
+		return nil, false
+	}))
+	report := scope.Validate(CallTarget(reflect.TypeFor[func(A)]()))
+	if err := report.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateStrict(t *testing.T) {
+	type A int
+	scope := New(func() A {
+		return 1
+	}).Strict()
+	report := scope.Validate(
+		func() A {
+			return 2
+		},
+		Override(func() A {
+			return 3
+		}),
+	)
+	// redefinition, and duplicate of the new definitions
+	if len(report.Errors) != 1 || !errors.Is(report.Errors[0], ErrBadDefinition) {
+		t.Fatalf("got %v", report.Errors)
+	}
+	if len(report.Duplicates) != 1 {
+		t.Fatalf("got %v", report.Duplicates)
+	}
+}
+
+func TestValidateBadDefinitions(t *testing.T) {
+	type A int
+	report := New().Validate(
+		func() {},
+		(*A)(nil),
+		func() A {
+			return 1
+		},
+	)
+	if len(report.Errors) != 2 {
+		t.Fatalf("got %v", report.Errors)
+	}
+	for _, err := range report.Errors {
+		if !errors.Is(err, ErrBadArgument) {
+			t.Fatalf("got %v", err)
+		}
+	}
+
+	// definitions that cannot be prepared
+	report = New().Validate(nil)
+	if len(report.Errors) != 1 || !errors.Is(report.Errors[0], ErrBadArgument) {
+		t.Fatalf("got %v", report.Errors)
+	}
+}
+
+func TestValidateContracts(t *testing.T) {
+	report := New().Validate(new(testContractRequiringModule))
+	if err := report.Err(); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestValidateStatic(t *testing.T) {
+	type Profile string
+	type Missing int
+	type Service int
+	scope := New(func() Profile {
+		panic("should not run")
+	})
+
+	report := scope.Validate(
+		// predicates are not evaluated
+		When(
+			func(p Profile) bool {
+				panic("should not run")
+			},
+			func() Service {
+				return 1
+			},
+		),
+		// both alternatives are analyzed, without duplicates
+		When(
+			func(p Profile, m Missing) bool {
+				panic("should not run")
+			},
+			func() Service {
+				return 2
+			},
+		),
+	)
+	if len(report.Duplicates) != 0 {
+		t.Fatalf("got %v", report.Duplicates)
+	}
+	if len(report.NotFound) != 1 || report.NotFound[0].Type != reflect.TypeFor[Missing]() {
+		t.Fatalf("got %v", report.NotFound)
+	}
+	if !strings.Contains(report.NotFound[0].Source, "predicate") {
+		t.Fatalf("got %v", report.NotFound[0].Source)
+	}
+
+	// duplicates with unconditional definitions are reported
+	report = scope.Validate(
+		When(
+			func(p Profile) bool {
+				panic("should not run")
+			},
+			func() Service {
+				return 1
+			},
+		),
+		func() Service {
+			return 2
+		},
+	)
+	if len(report.Duplicates) != 1 {
+		t.Fatalf("got %v", report.Duplicates)
+	}
+
+	// configuration fields are not resolved
+	report = New(func() testConfigEnv {
+		panic("should not run")
+	}).Validate(new(testConfigModule))
+	if err := report.Err(); err != nil {
+		t.Fatal(err)
+	}
+	// reported for the field and for the method depending on it
+	report = New().Validate(new(testConfigPresetModule))
+	if len(report.NotFound) != 2 {
+		t.Fatalf("got %v", report.NotFound)
+	}
+	for _, err := range report.NotFound {
+		if err.Type != reflect.TypeFor[testConfigEnv]() {
+			t.Fatalf("got %v", err)
+		}
+	}
+}
+
+func TestValidationTargetBadArguments(t *testing.T) {
+	for _, fn := range []func(){
+		func() { CallTarget(nil) },
+		func() { CallTarget(42) },
+		func() { InjectTarget(nil) },
+		func() { InjectTarget(new(int)) },
+	} {
+		if err := recoverError(t, fn); !errors.Is(err, ErrBadArgument) {
+			t.Fatalf("got %v", err)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package dscope
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+const TheoryOfScopeRemoval = `
+dscope removal theory:
+- Without returns a child scope in which the given types are unresolvable:
+  they are removed from the value stack and excluded from resolvers.
+- Removal is checked like a Fork: every remaining definition depending on a
+  removed type is reported, all at once, as a missing dependency.
+- WithoutDependents instead removes those definitions too, transitively, so
+  the result is always consistent.
+- Remaining values keep their initializers and cached results, since their
+  dependencies are unchanged. Opaque consumers are kept and fail at runtime if
+  they resolve a removed type.
+- The child scope has its own signature, derived from the parent signature and
+  the removed types, so forks of it never share cached forkers with forks of
+  the parent.
+- Without may remove some outputs of a multi-output definition and keep the
+  others. Fork signatures cover the provided types, not only the definition
+  types, so forks of such a scope never share cached forkers with forks of a
+  scope providing all outputs.
+`
+
+// Without returns a child scope in which types have no definitions. It panics
+// with ErrDependencyNotFound, listing every remaining definition that depends
+// on a removed type.
+func (scope Scope) Without(types ...reflect.Type) Scope {
+	return scope.without(types, false)
+}
+
+// WithoutDependents returns a child scope in which types, and every definition
+// depending on them directly or transitively, have no definitions.
+func (scope Scope) WithoutDependents(types ...reflect.Type) Scope {
+	return scope.without(types, true)
+}
+
+func (scope Scope) without(types []reflect.Type, pruneDependents bool) Scope {
+	removed := make(map[_TypeID]struct{}, len(types))
+	for _, t := range types {
+		if t == nil {
+			panic(&BadArgumentError{
+				Reason: "nil type",
+			})
+		}
+		id := getTypeID(t)
+		if isAlwaysProvided(id) {
+			panic(&BadArgumentError{
+				Reason: fmt.Sprintf("cannot remove built-in %v", t),
+			})
+		}
+		removed[id] = struct{}{}
+	}
+	return scope.withoutIDs(removed, pruneDependents)
+}
+
+// withoutIDs is like without, for type IDs.
+func (scope Scope) withoutIDs(removed map[_TypeID]struct{}, pruneDependents bool) Scope {
+	if pruneDependents {
+		dependents := make(map[_TypeID][]_TypeID)
+		for value := range scope.values.IterValues() {
+			for _, depID := range value.typeInfo.Dependencies {
+				dependents[depID] = append(dependents[depID], value.typeInfo.TypeID)
+			}
+		}
+		queue := slices.Collect(maps.Keys(removed))
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, dependent := range dependents[id] {
+				if _, ok := removed[dependent]; ok {
+					continue
+				}
+				removed[dependent] = struct{}{}
+				queue = append(queue, dependent)
+			}
+		}
+
+	} else {
+		var errs []error
+		for value := range scope.values.IterValues() {
+			if _, ok := removed[value.typeInfo.TypeID]; ok {
+				continue
+			}
+			for _, depID := range value.typeInfo.Dependencies {
+				if _, ok := removed[depID]; ok {
+					errs = append(errs, &DependencyNotFoundError{
+						Type:       typeIDToType(depID),
+						RequiredBy: value.typeInfo.DefType,
+						Source:     valueSource(value),
+					})
+				}
+			}
+		}
+		if len(errs) > 0 {
+			panic(errors.Join(errs...))
+		}
+	}
+
+	var values []_Value
+	for value := range scope.values.IterValues() {
+		if _, ok := removed[value.typeInfo.TypeID]; ok {
+			continue
+		}
+		values = append(values, value)
+	}
+	slices.SortFunc(values, func(a, b _Value) int {
+		return cmp.Compare(a.typeInfo.TypeID, b.typeInfo.TypeID)
+	})
+
+	removedIDs := slices.Sorted(maps.Keys(removed))
+	h := sha256.New()
+	h.Write(scope.signature[:])
+	h.Write([]byte("without"))
+	buf := make([]byte, 0, len(removedIDs)*8)
+	for _, id := range removedIDs {
+		buf = binary.NativeEndian.AppendUint64(buf, uint64(id))
+	}
+	if _, err := h.Write(buf); err != nil {
+		panic(fmt.Errorf("unexpected error during signature hash calculation in Scope.Without: %w", err))
+	}
+	var signature _Hash
+	h.Sum(signature[:0])
+
+	var resolvers *_Resolvers
+	if scope.resolvers != nil {
+		excluded := maps.Clone(scope.resolvers.Excluded)
+		if excluded == nil {
+			excluded = make(map[_TypeID]struct{}, len(removed))
+		}
+		maps.Copy(excluded, removed)
+		resolvers = newResolvers(scope.resolvers.Funcs, excluded)
+	}
+
+	return Scope{
+		values: &_StackedMap{
+			Values: values,
+			Height: 1,
+		},
+		signature:   signature,
+		forkFuncKey: scope.forkFuncKey,
+		resolvers:   resolvers,
+		strict:      scope.strict,
+		resolving:   scope.resolving,
+		modules:     scope.modules,
+	}
+}
@@ -0,0 +1,182 @@
+package dscope
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithout(t *testing.T) {
+	type Cache string
+	type Service string
+	type Handler string
+	scope := New(
+		func() Cache { return "cache" },
+		func() Service { return "service" },
+		func(s Service) Handler { return Handler(s) },
+		func(s Scope) int {
+			_, ok := s.Get(reflect.TypeFor[Cache]())
+			if ok {
+				return 1
+			}
+			return 0
+		},
+	)
+	handler := Get[Handler](scope)
+
+	without := scope.Without(reflect.TypeFor[Cache]())
+	if _, ok := without.Get(reflect.TypeFor[Cache]()); ok {
+		t.Fatal("removed type should not be resolvable")
+	}
+	if Get[Handler](without) != handler {
+		t.Fatal()
+	}
+	if Get[int](without) != 0 {
+		t.Fatal("opaque consumer should not see removed type")
+	}
+	for typ := range without.AllTypes() {
+		if typ == reflect.TypeFor[Cache]() {
+			t.Fatal("removed type listed")
+		}
+	}
+	if without.signature == scope.signature {
+		t.Fatal("signature should change")
+	}
+
+	// the parent is unaffected
+	if Get[Cache](scope) != "cache" {
+		t.Fatal()
+	}
+
+	// children may define the type again
+	if Get[Cache](without.Fork(func() Cache { return "new" })) != "new" {
+		t.Fatal()
+	}
+}
+
+func TestWithoutMissingDependencies(t *testing.T) {
+	type A int
+	type B int
+	type C int
+	scope := New(
+		func() A { return 1 },
+		func(A) B { return 2 },
+		func(A) C { return 3 },
+	)
+	defer func() {
+		p := recover()
+		if p == nil {
+			t.Fatal("should panic")
+		}
+		err, ok := p.(error)
+		if !ok || !errors.Is(err, ErrDependencyNotFound) {
+			t.Fatalf("got %v", p)
+		}
+		// every broken definition is reported
+		msg := err.Error()
+		if !strings.Contains(msg, "dscope.B") || !strings.Contains(msg, "dscope.C") {
+			t.Fatalf("got %v", msg)
+		}
+	}()
+	scope.Without(reflect.TypeFor[A]())
+}
+
+func TestWithoutPartialDefinition(t *testing.T) {
+	type A int
+	type B int
+	type C int
+	type E int
+	scope := New(func() (A, B) {
+		return 1, 2
+	})
+	def := func(a A) E {
+		return E(a)
+	}
+
+	// warm the forker cache from the full scope
+	if e := Get[E](scope.Fork(func() C { return 3 }).Fork(def)); e != 1 {
+		t.Fatalf("got %v", e)
+	}
+
+	// B is kept without A, so forks must not reuse the forkers of the full scope
+	without := scope.Without(reflect.TypeFor[A]())
+	if b := Get[B](without); b != 2 {
+		t.Fatalf("got %v", b)
+	}
+	child := without.Fork(func() C { return 3 })
+	if err := recoverError(t, func() {
+		child.Fork(def)
+	}); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestWithoutDependents(t *testing.T) {
+	type A int
+	type B int
+	type C int
+	type D int
+	var calls int
+	scope := New(
+		func() A { return 1 },
+		func(A) B { return 2 },
+		func(B) C { return 3 },
+		func() D {
+			calls++
+			return 4
+		},
+	)
+	Get[D](scope)
+	without := scope.WithoutDependents(reflect.TypeFor[A]())
+	for _, typ := range []reflect.Type{
+		reflect.TypeFor[A](),
+		reflect.TypeFor[B](),
+		reflect.TypeFor[C](),
+	} {
+		if _, ok := without.Get(typ); ok {
+			t.Fatalf("%v should be removed", typ)
+		}
+	}
+	// cached values are kept
+	Get[D](without)
+	if calls != 1 {
+		t.Fatalf("got %d", calls)
+	}
+}
+
+func TestWithoutResolvers(t *testing.T) {
+	scope := New(Resolver(func(t reflect.Type) (any, bool) {
+		if t != reflect.TypeFor[int]() {
+			return nil, false
+		}
+		return Provide(42), true
+	}))
+	if Get[int](scope) != 42 {
+		t.Fatal()
+	}
+	without := scope.Without(reflect.TypeFor[int]())
+	if _, ok := without.Get(reflect.TypeFor[int]()); ok {
+		t.Fatal("removed type should not be resolved")
+	}
+	if _, ok := without.Fork().Get(reflect.TypeFor[int]()); ok {
+		t.Fatal("removed type should not be resolved in children")
+	}
+}
+
+func TestWithoutBadArgument(t *testing.T) {
+	for _, typ := range []reflect.Type{nil, reflect.TypeFor[Fork]()} {
+		func() {
+			defer func() {
+				p := recover()
+				if p == nil {
+					t.Fatal("should panic")
+				}
+				if err, ok := p.(error); !ok || !errors.Is(err, ErrBadArgument) {
+					t.Fatalf("got %v", p)
+				}
+			}()
+			New().Without(typ)
+		}()
+	}
+}